@@ -0,0 +1,361 @@
+// The MIT License (MIT)
+//
+// Copyright (c) 2015 Dylan Carney
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"sort"
+)
+
+func readModel(path string) (*apiModel, error) {
+	b, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var m apiModel
+	if err := json.Unmarshal(b, &m); err != nil {
+		return nil, fmt.Errorf("%s: %s", path, err)
+	}
+	return &m, nil
+}
+
+// A change is a single difference between two versions of an API.
+type change struct {
+	Breaking bool
+	Endpoint string // the method and URL of the affected endpoint
+	Text     string
+}
+
+// diffCommand implements the diff command, which writes a Markdown changelog
+// of the differences between two JSON models, or between the endpoints
+// documented in a set of Go files at two git revisions.
+func diffCommand(args []string) {
+	fs := flag.NewFlagSet("diff", flag.ExitOnError)
+	out := fs.String("out", "", "Name of the file to write the changelog to. Defaults to stdout.")
+	failOnBreaking := fs.Bool("fail-on-breaking", false, "Exit non-zero when any breaking change is found.")
+	oldRev := fs.String("old-rev", "", "Compares the given Go files as of this git revision, instead of two JSON models.")
+	newRev := fs.String("new-rev", "", "The git revision to compare -old-rev against. Defaults to the working tree.")
+	fs.Var(&opts.verbs, "verbs", "Comma-separated list of HTTP methods to recognize besides the standard ones, e.g. PROPFIND,REPORT,QUERY.")
+	fs.Parse(args)
+
+	var oldModel, newModel *apiModel
+	if *oldRev != "" {
+		opts.rev = *oldRev
+		oldModel = loadFiles(fs.Args())
+		opts.rev = *newRev
+		newModel = loadFiles(fs.Args())
+	} else {
+		if fs.NArg() != 2 {
+			log.Fatalf("usage: apidoc diff [flags] old.json new.json, or apidoc diff -old-rev=rev [flags] files...")
+		}
+		var err error
+		if oldModel, err = readModel(fs.Arg(0)); err != nil {
+			log.Fatalf("could not read old model: %s", err)
+		}
+		if newModel, err = readModel(fs.Arg(1)); err != nil {
+			log.Fatalf("could not read new model: %s", err)
+		}
+	}
+
+	changes := diffModels(oldModel, newModel)
+	var b bytes.Buffer
+	writeChangelog(&b, changes)
+	if *out == "" {
+		os.Stdout.Write(b.Bytes())
+	} else if _, err := writeFile(*out, b.Bytes()); err != nil {
+		log.Fatalf("could not write output file: %s", err)
+	}
+
+	if *failOnBreaking {
+		for _, c := range changes {
+			if c.Breaking {
+				log.Fatalf("found breaking API changes")
+			}
+		}
+	}
+}
+
+// diffModels compares the endpoints of two models.  Endpoints are matched by
+// their apidoc(name), falling back to their method and URL, so that renaming
+// either one alone isn't reported as a removal.
+func diffModels(oldModel, newModel *apiModel) []change {
+	var changes []change
+	matched := map[*Endpoint]bool{}
+	pairs := map[*Endpoint]*Endpoint{}
+
+	byName := map[string]*Endpoint{}
+	byRoute := map[string]*Endpoint{}
+	for _, e := range newModel.Endpoints {
+		byName[e.Name] = e
+		byRoute[e.Method+" "+canonicalPath(e.URLTemplate)] = e
+	}
+	for _, e := range oldModel.Endpoints {
+		if n, ok := byName[e.Name]; ok && !matched[n] {
+			pairs[e] = n
+			matched[n] = true
+		}
+	}
+	for _, e := range oldModel.Endpoints {
+		if _, ok := pairs[e]; ok {
+			continue
+		}
+		if n, ok := byRoute[e.Method+" "+canonicalPath(e.URLTemplate)]; ok && !matched[n] {
+			pairs[e] = n
+			matched[n] = true
+		}
+	}
+
+	for _, e := range oldModel.Endpoints {
+		if n, ok := pairs[e]; ok {
+			changes = append(changes, diffEndpoints(e, n)...)
+		} else {
+			changes = append(changes, change{true, route(e), "removed"})
+		}
+	}
+	for _, e := range newModel.Endpoints {
+		if !matched[e] {
+			changes = append(changes, change{false, route(e), "added"})
+		}
+	}
+	return changes
+}
+
+func route(e *Endpoint) string {
+	return fmt.Sprintf("%s %s (%s)", e.Method, e.URLTemplate, e.Name)
+}
+
+// diffEndpoints compares two versions of the same endpoint.
+func diffEndpoints(o, n *Endpoint) []change {
+	var changes []change
+	add := func(breaking bool, format string, args ...interface{}) {
+		changes = append(changes, change{breaking, route(o), fmt.Sprintf(format, args...)})
+	}
+
+	// endpoints with several routes are compared route by route
+	if len(o.AllRoutes()) == 1 && len(n.AllRoutes()) == 1 {
+		if o.Method != n.Method {
+			add(true, "method changed from %s to %s", o.Method, n.Method)
+		}
+		if canonicalPath(o.URLTemplate) != canonicalPath(n.URLTemplate) {
+			add(true, "URL changed from `%s` to `%s`", o.URLTemplate, n.URLTemplate)
+		}
+	} else {
+		oldRoutes, newRoutes := routeSet(o), routeSet(n)
+		for _, r := range o.AllRoutes() {
+			if !newRoutes[r.Method+" "+canonicalPath(r.URLTemplate)] {
+				add(true, "route `%s` removed", r)
+			}
+		}
+		for _, r := range n.AllRoutes() {
+			if !oldRoutes[r.Method+" "+canonicalPath(r.URLTemplate)] {
+				add(false, "route `%s` added", r)
+			}
+		}
+	}
+	if o.Name != n.Name {
+		add(false, "renamed from %s to %s", o.Name, n.Name)
+	}
+
+	changes = append(changes, diffParams(route(o), "parameter", o.URLParams, n.URLParams)...)
+	changes = append(changes, diffParams(route(o), "body parameter", o.DataParams, n.DataParams)...)
+
+	if !o.Deprecated && n.Deprecated {
+		add(false, "deprecated%s", deprecationNote(n))
+	}
+	if o.Deprecated && n.Deprecated && o.Sunset != n.Sunset && n.Sunset != "" {
+		add(false, "sunset date set to %s", n.Sunset)
+	}
+
+	if o.Auth.String() != n.Auth.String() {
+		add(authBreaking(o.Auth, n.Auth), "auth changed from `%s` to `%s`", o.Auth, n.Auth)
+	}
+
+	if o.SuccessResponse.Code != n.SuccessResponse.Code {
+		add(true, "success response changed from %d to %d", o.SuccessResponse.Code, n.SuccessResponse.Code)
+	}
+	oldCodes, newCodes := responseCodes(o.ErrorResponses), responseCodes(n.ErrorResponses)
+	for _, code := range sortedCodes(oldCodes) {
+		if !newCodes[code] {
+			add(true, "error response %d removed", code)
+		}
+	}
+	for _, code := range sortedCodes(newCodes) {
+		if !oldCodes[code] {
+			add(false, "error response %d added", code)
+		}
+	}
+	return changes
+}
+
+// routeSet returns the set of the routes of an endpoint, in canonical form.
+func routeSet(e *Endpoint) map[string]bool {
+	routes := map[string]bool{}
+	for _, r := range e.AllRoutes() {
+		routes[r.Method+" "+canonicalPath(r.URLTemplate)] = true
+	}
+	return routes
+}
+
+// deprecationNote describes the replacement and sunset date of a deprecated
+// endpoint, if it has them.
+func deprecationNote(e *Endpoint) string {
+	var note string
+	if e.Replacement != "" {
+		note += fmt.Sprintf(", use %s instead", e.Replacement)
+	}
+	if e.Sunset != "" {
+		note += fmt.Sprintf(", to be removed on %s", e.Sunset)
+	}
+	return note
+}
+
+// authBreaking reports whether a change of auth shuts out existing clients:
+// a different scheme, or additional scopes.  Declaring the auth of an endpoint
+// for the first time only documents it, and dropping the need for auth is
+// always compatible.
+func authBreaking(o, n *Auth) bool {
+	if o == nil || !n.Required() {
+		return false
+	}
+	if o.Scheme != n.Scheme {
+		return true
+	}
+	scopes := map[string]bool{}
+	for _, scope := range o.Scopes {
+		scopes[scope] = true
+	}
+	for _, scope := range n.Scopes {
+		if !scopes[scope] {
+			return true
+		}
+	}
+	return false
+}
+
+// diffParams compares two versions of the parameters of an endpoint.  New
+// required parameters, parameters that became required, removed parameters
+// and changed types all break existing clients.
+func diffParams(endpoint, kind string, o, n []Parameter) []change {
+	var changes []change
+	add := func(breaking bool, format string, args ...interface{}) {
+		changes = append(changes, change{breaking, endpoint, fmt.Sprintf(format, args...)})
+	}
+
+	params := map[string]Parameter{}
+	for _, p := range n {
+		params[p.Name] = p
+	}
+	for _, op := range o {
+		np, ok := params[op.Name]
+		delete(params, op.Name)
+		switch {
+		case !ok:
+			add(true, "%s `%s` removed", kind, op.Name)
+			continue
+		case !op.Required && np.Required:
+			add(true, "%s `%s` became required", kind, op.Name)
+		case op.Required && !np.Required:
+			add(false, "%s `%s` became optional", kind, op.Name)
+		}
+		if !sameType(op.Type, np.Type) {
+			add(true, "%s `%s` type changed from %q to %q", kind, op.Name, op.Type, np.Type)
+		}
+	}
+	for _, p := range n {
+		if _, ok := params[p.Name]; !ok {
+			continue
+		}
+		if p.Required {
+			add(true, "required %s `%s` added", kind, p.Name)
+		} else {
+			add(false, "%s `%s` added", kind, p.Name)
+		}
+	}
+	return changes
+}
+
+func responseCodes(rs []Response) map[int]bool {
+	codes := map[int]bool{}
+	for _, r := range rs {
+		codes[r.Code] = true
+	}
+	return codes
+}
+
+func sortedCodes(codes map[int]bool) []int {
+	var sorted []int
+	for code := range codes {
+		sorted = append(sorted, code)
+	}
+	sort.Ints(sorted)
+	return sorted
+}
+
+// writeChangelog writes changes as a Markdown changelog, with the breaking
+// changes first.
+func writeChangelog(w io.Writer, changes []change) {
+	fmt.Fprintln(w, "## API changes")
+	if len(changes) == 0 {
+		fmt.Fprintln(w, "\nNo changes.")
+		return
+	}
+
+	for _, section := range []struct {
+		title    string
+		breaking bool
+	}{
+		{"Breaking changes", true},
+		{"Non-breaking changes", false},
+	} {
+		var lines []string
+		for _, c := range changes {
+			if c.Breaking == section.breaking {
+				lines = append(lines, fmt.Sprintf("* `%s`: %s", c.Endpoint, c.Text))
+			}
+		}
+		if len(lines) == 0 {
+			continue
+		}
+		fmt.Fprintf(w, "\n### %s\n\n", section.title)
+		for _, line := range lines {
+			fmt.Fprintln(w, line)
+		}
+	}
+}
+
+// sameType reports whether two parameter types are the same, however they are
+// spelled, so that models written before types were normalized still compare.
+func sameType(a, b string) bool {
+	ta, okA := parseParamType(a)
+	tb, okB := parseParamType(b)
+	if okA && okB {
+		return ta.String() == tb.String()
+	}
+	return a == b
+}
@@ -0,0 +1,166 @@
+// The MIT License (MIT)
+//
+// Copyright (c) 2015 Dylan Carney
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package main
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestDiffModels(t *testing.T) {
+	getUser := func() *Endpoint {
+		return &Endpoint{
+			Name:            "getUser",
+			Method:          "GET",
+			URLTemplate:     "/users/:id",
+			URLParams:       []Parameter{{Name: "id", Required: true, Type: "integer"}, {Name: "fields", Type: "string"}},
+			SuccessResponse: Response{Code: 200},
+			ErrorResponses:  []Response{{Code: 404}},
+			Auth:            &Auth{Scheme: "bearer", Scopes: []string{"users.read"}},
+		}
+	}
+	const route = "GET /users/:id (getUser)"
+
+	tests := []struct {
+		name   string
+		change func(e *Endpoint)
+		want   []change
+	}{
+		{
+			name:   "unchanged",
+			change: func(e *Endpoint) {},
+		},
+		{
+			name:   "param syntax changed",
+			change: func(e *Endpoint) { e.URLTemplate = "/users/{id}" },
+		},
+		{
+			name:   "type spelling changed",
+			change: func(e *Endpoint) { e.URLParams[0].Type = "int" },
+		},
+		{
+			name:   "type changed",
+			change: func(e *Endpoint) { e.URLParams[0].Type = "string" },
+			want:   []change{{true, route, "parameter `id` type changed from \"integer\" to \"string\""}},
+		},
+		{
+			name:   "method changed",
+			change: func(e *Endpoint) { e.Method = "POST" },
+			want:   []change{{true, route, "method changed from GET to POST"}},
+		},
+		{
+			name:   "URL changed",
+			change: func(e *Endpoint) { e.URLTemplate = "/people/:id" },
+			want:   []change{{true, route, "URL changed from `/users/:id` to `/people/:id`"}},
+		},
+		{
+			name:   "optional param added",
+			change: func(e *Endpoint) { e.URLParams = append(e.URLParams, Parameter{Name: "limit"}) },
+			want:   []change{{false, route, "parameter `limit` added"}},
+		},
+		{
+			name:   "required param added",
+			change: func(e *Endpoint) { e.DataParams = append(e.DataParams, Parameter{Name: "name", Required: true}) },
+			want:   []change{{true, route, "required body parameter `name` added"}},
+		},
+		{
+			name:   "param removed",
+			change: func(e *Endpoint) { e.URLParams = e.URLParams[:1] },
+			want:   []change{{true, route, "parameter `fields` removed"}},
+		},
+		{
+			name:   "param became required",
+			change: func(e *Endpoint) { e.URLParams[1].Required = true },
+			want:   []change{{true, route, "parameter `fields` became required"}},
+		},
+		{
+			name:   "param became optional",
+			change: func(e *Endpoint) { e.URLParams[0].Required = false },
+			want:   []change{{false, route, "parameter `id` became optional"}},
+		},
+		{
+			name:   "renamed",
+			change: func(e *Endpoint) { e.Name = "fetchUser" },
+			want:   []change{{false, route, "renamed from getUser to fetchUser"}},
+		},
+		{
+			name:   "deprecated",
+			change: func(e *Endpoint) { e.Deprecated, e.Replacement = true, "getUserV2" },
+			want:   []change{{false, route, "deprecated, use getUserV2 instead"}},
+		},
+		{
+			name:   "scope added",
+			change: func(e *Endpoint) { e.Auth.Scopes = append(e.Auth.Scopes, "admin") },
+			want:   []change{{true, route, "auth changed from `bearer scope:users.read` to `bearer scope:users.read scope:admin`"}},
+		},
+		{
+			name:   "auth dropped",
+			change: func(e *Endpoint) { e.Auth = &Auth{Scheme: AuthNone} },
+			want:   []change{{false, route, "auth changed from `bearer scope:users.read` to `none`"}},
+		},
+		{
+			name:   "success code changed",
+			change: func(e *Endpoint) { e.SuccessResponse.Code = 201 },
+			want:   []change{{true, route, "success response changed from 200 to 201"}},
+		},
+		{
+			name:   "error responses changed",
+			change: func(e *Endpoint) { e.ErrorResponses = []Response{{Code: 410}} },
+			want:   []change{{true, route, "error response 404 removed"}, {false, route, "error response 410 added"}},
+		},
+		{
+			name: "route added",
+			change: func(e *Endpoint) {
+				e.Routes = []Route{{"GET", "/users/:id"}, {"GET", "/people/:id"}}
+			},
+			want: []change{{false, route, "route `GET /people/:id` added"}},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			n := getUser()
+			tt.change(n)
+			got := diffModels(&apiModel{Endpoints: []*Endpoint{getUser()}}, &apiModel{Endpoints: []*Endpoint{n}})
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("diffModels() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestDiffModelsMatching(t *testing.T) {
+	a := &Endpoint{Name: "a", Method: "GET", URLTemplate: "/a"}
+	b := &Endpoint{Name: "b", Method: "GET", URLTemplate: "/b"}
+	renamedB := &Endpoint{Name: "bee", Method: "GET", URLTemplate: "/b"}
+	c := &Endpoint{Name: "c", Method: "GET", URLTemplate: "/c"}
+
+	got := diffModels(&apiModel{Endpoints: []*Endpoint{a, b}}, &apiModel{Endpoints: []*Endpoint{renamedB, c}})
+	want := []change{
+		{true, "GET /a (a)", "removed"},
+		{false, "GET /b (b)", "renamed from b to bee"},
+		{false, "GET /c (c)", "added"},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("diffModels() = %+v, want %+v", got, want)
+	}
+}
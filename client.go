@@ -0,0 +1,298 @@
+// The MIT License (MIT)
+//
+// Copyright (c) 2015 Dylan Carney
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package main
+
+import (
+	"bytes"
+	"flag"
+	"fmt"
+	"go/format"
+	"go/token"
+	"go/types"
+	"log"
+	"net/http"
+	"sort"
+	"strings"
+	"text/template"
+	"unicode"
+)
+
+// clientFile is the data that clientTemplate is rendered with.
+type clientFile struct {
+	Package string
+	Methods []clientMethod
+	Errors  []clientError
+	HasBody bool
+}
+
+// clientMethod is a Client method that calls a single Endpoint.
+type clientMethod struct {
+	Name     string
+	Doc      []string
+	Method   string
+	Path     string // a fmt format string, with a %s for each path arg
+	PathArgs []clientField
+	Options  []clientField
+	HasBody  bool
+}
+
+// clientField is a parameter of a Client method, either as a function
+// argument or as a field of the method's options struct.
+type clientField struct {
+	Name     string // the argument name
+	Field    string // the struct field name
+	Param    string // the documented parameter name
+	Type     string
+	Doc      string
+	Required bool
+	Query    bool
+	Wildcard bool   // whether a path argument is the rest of the path
+	NonZero  string // an expression that is true when the field is set
+}
+
+// clientError is a sentinel error for a documented error response code.
+type clientError struct {
+	Name string
+	Code int
+	Text string
+}
+
+// genClient implements the gen-client command, which writes a typed Go client
+// package for the endpoints documented in the given files.
+func genClient(args []string) {
+	fs := flag.NewFlagSet("gen-client", flag.ExitOnError)
+	out := fs.String("out", "client.go", "Name of the output file to use.")
+	pkg := fs.String("pkg", "client", "Package name of the generated client.")
+	fs.BoolVar(&opts.check, "check", false, "Generates the output without writing it, printing a diff and exiting non-zero if the existing output file is out of date.")
+	fs.StringVar(&opts.rev, "rev", "", "Reads the input files as of a git revision from the repository's object store, instead of from the working tree.")
+	fs.BoolVar(&opts.strict, "strict", false, "Enables validation checks on each apidoc comment block. When strict is true, any validation error causes the process to exit.")
+	fs.Var(&opts.verbs, "verbs", "Comma-separated list of HTTP methods to recognize besides the standard ones, e.g. PROPFIND,REPORT,QUERY.")
+	fs.Parse(args)
+
+	endpoints := loadFiles(fs.Args()).Endpoints
+	src, err := generateClient(*pkg, endpoints)
+	if err != nil {
+		log.Fatalf("could not generate client: %s", err)
+	}
+	if err := emit(*out, src); err != nil {
+		log.Fatalf("could not write output file: %s", err)
+	}
+	exitIfStale()
+}
+
+// generateClient renders the source of a Go client package for endpoints.
+func generateClient(pkg string, endpoints []*Endpoint) ([]byte, error) {
+	f := clientFile{Package: pkg}
+	names := map[string]bool{}
+	codes := map[int]bool{}
+	for _, e := range endpoints {
+		if e.Method == "" || e.URLTemplate == "" {
+			log.Printf("skipping apidoc(%s): missing method or URL\n", e.Name)
+			continue
+		}
+
+		m := newClientMethod(e)
+		if names[m.Name] {
+			return nil, fmt.Errorf("duplicate method name %s from apidoc(%s)", m.Name, e.Name)
+		}
+		names[m.Name] = true
+		f.Methods = append(f.Methods, m)
+		f.HasBody = f.HasBody || m.HasBody
+
+		for _, r := range e.ErrorResponses {
+			codes[r.Code] = true
+		}
+	}
+
+	for code := range codes {
+		f.Errors = append(f.Errors, newClientError(code))
+	}
+	sort.Slice(f.Errors, func(i, j int) bool { return f.Errors[i].Code < f.Errors[j].Code })
+
+	var b bytes.Buffer
+	t := template.Must(template.New("client").Parse(clientTemplate))
+	if err := t.Execute(&b, f); err != nil {
+		return nil, err
+	}
+	return format.Source(b.Bytes())
+}
+
+// clientDeprecationNote describes the replacement and sunset date of a
+// deprecated endpoint, naming the replacement with the given function.
+func clientDeprecationNote(e *Endpoint, name func(string) string) string {
+	var note string
+	if e.Sunset != "" {
+		note += " and may be removed after " + e.Sunset
+	}
+	if e.Replacement != "" {
+		note += ", use " + name(e.Replacement) + " instead"
+	}
+	return note
+}
+
+func newClientMethod(e *Endpoint) clientMethod {
+	m := clientMethod{
+		Name:    exportedName(e.Name),
+		Method:  e.Method,
+		HasBody: len(e.DataParams) > 0,
+	}
+
+	m.Doc = append(m.Doc, fmt.Sprintf("%s calls %s %s.", m.Name, e.Method, e.URLTemplate))
+	if e.Description != "" {
+		m.Doc = append(m.Doc, "")
+		m.Doc = append(m.Doc, strings.Split(strings.TrimSpace(e.Description), "\n")...)
+	}
+	if len(e.ErrorResponses) > 0 {
+		var errs []string
+		for _, r := range e.ErrorResponses {
+			errs = append(errs, newClientError(r.Code).Name)
+		}
+		m.Doc = append(m.Doc, "", "Documented errors: "+strings.Join(errs, ", ")+".")
+	}
+	if e.Deprecated {
+		m.Doc = append(m.Doc, "", "Deprecated: "+exportedName(e.Name)+" is deprecated"+clientDeprecationNote(e, exportedName)+".")
+	}
+
+	splits := strings.Split(e.URLTemplate, "/")
+	for i, seg := range pathSegments(e.URLTemplate) {
+		if seg.End {
+			splits[i] = ""
+			continue
+		}
+		if seg.Param == "" {
+			splits[i] = strings.Replace(seg.Literal, "%", "%%", -1)
+			continue
+		}
+		p := Parameter{Name: seg.Param}
+		for _, param := range e.URLParams {
+			if param.Name == p.Name {
+				p = param
+			}
+		}
+		f := newClientField(p)
+		f.Wildcard = seg.Wildcard
+		m.PathArgs = append(m.PathArgs, f)
+		splits[i] = "%s"
+	}
+	m.Path = strings.Join(splits, "/")
+
+	for _, p := range e.QueryParams() {
+		f := newClientField(p)
+		f.Query = true
+		m.Options = append(m.Options, f)
+	}
+	for _, p := range e.DataParams {
+		m.Options = append(m.Options, newClientField(p))
+	}
+	return m
+}
+
+func newClientField(p Parameter) clientField {
+	f := clientField{
+		Name:     localName(p.Name),
+		Field:    exportedName(p.Name),
+		Param:    p.Name,
+		Type:     goType(p.Type),
+		Required: p.Required,
+	}
+	if p.Description != "" {
+		f.Doc = strings.TrimSpace(p.Description)
+	}
+
+	field := "opts." + f.Field
+	switch {
+	case f.Type == "string":
+		f.NonZero = field + ` != ""`
+	case f.Type == "int" || f.Type == "float64":
+		f.NonZero = field + " != 0"
+	case f.Type == "bool":
+		f.NonZero = field
+	case strings.HasPrefix(f.Type, "[]") || strings.HasPrefix(f.Type, "map"):
+		f.NonZero = "len(" + field + ") > 0"
+	default:
+		f.NonZero = field + " != nil"
+	}
+	return f
+}
+
+func newClientError(code int) clientError {
+	text := http.StatusText(code)
+	if text == "" {
+		return clientError{Name: fmt.Sprintf("ErrStatus%d", code), Code: code, Text: fmt.Sprintf("status %d", code)}
+	}
+	return clientError{Name: "Err" + exportedName(text), Code: code, Text: strings.ToLower(text)}
+}
+
+// goType maps a documented parameter type onto a Go type.
+func goType(typ string) string {
+	if t, ok := parseParamType(typ); ok {
+		return t.goType()
+	}
+	return "interface{}"
+}
+
+// exportedName turns a name such as "get-user" into an exported Go
+// identifier, e.g. "GetUser".
+func exportedName(name string) string {
+	var b strings.Builder
+	upper := true
+	for _, r := range name {
+		if !unicode.IsLetter(r) && !unicode.IsDigit(r) {
+			upper = true
+			continue
+		}
+		if upper {
+			r = unicode.ToUpper(r)
+			upper = false
+		}
+		b.WriteRune(r)
+	}
+
+	s := b.String()
+	if s == "" || unicode.IsDigit([]rune(s)[0]) {
+		s = "X" + s
+	}
+	return s
+}
+
+// clientReserved are the identifiers that the methods of a generated client
+// use: their locals, and the packages that the client imports.  Path params
+// with these names would shadow them.
+var clientReserved = map[string]bool{
+	"c": true, "ctx": true, "opts": true, "path": true, "query": true, "body": true, "b": true, "err": true,
+	"bytes": true, "context": true, "errors": true, "fmt": true, "http": true, "io": true, "json": true, "strings": true, "url": true,
+}
+
+// localName turns a name such as "user-id" into an unexported Go identifier,
+// e.g. "userId", that doesn't collide with a keyword, a predeclared
+// identifier such as nil, or the generated code.
+func localName(name string) string {
+	r := []rune(exportedName(name))
+	r[0] = unicode.ToLower(r[0])
+	s := string(r)
+
+	if token.IsKeyword(s) || types.Universe.Lookup(s) != nil || clientReserved[s] {
+		return s + "Param"
+	}
+	return s
+}
@@ -0,0 +1,267 @@
+// The MIT License (MIT)
+//
+// Copyright (c) 2015 Dylan Carney
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package main
+
+import (
+	"errors"
+	"fmt"
+	"net/url"
+	"strings"
+)
+
+var (
+	ErrNotCurl          = errors.New("apidoc: example is not a curl command")
+	ErrCurlNoURL        = errors.New("apidoc: curl example has no URL")
+	ErrCurlUnterminated = errors.New("apidoc: curl example has an unterminated quote")
+)
+
+// A Request is the structured form of an example call to an Endpoint, as
+// parsed from a curl command.
+type Request struct {
+
+	// Method is the HTTP request verb, either given with -X or implied by the
+	// other flags
+	Method string
+
+	// URL is the URL that the request is sent to, as written in the example
+	URL string
+
+	// Headers are the request headers given with -H, in order
+	Headers []Header
+
+	// Body is the request body given with -d, --data, --data-raw, etc.
+	Body string
+
+	// Flags are any other curl flags (and their arguments), e.g. -v, -k
+	Flags []string
+}
+
+// A Header is a single HTTP request header.
+type Header struct {
+	Name  string
+	Value string
+}
+
+// String returns the request formatted as an HTTP/1.1 request message.
+func (r Request) String() string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "%s %s", r.Method, r.URL)
+	for _, h := range r.Headers {
+		fmt.Fprintf(&b, "\n%s: %s", h.Name, h.Value)
+	}
+	if r.Body != "" {
+		fmt.Fprintf(&b, "\n\n%s", r.Body)
+	}
+	return b.String()
+}
+
+// Path returns the path portion of the request URL.
+func (r Request) Path() string {
+	u, err := r.parseURL()
+	if err != nil {
+		return ""
+	}
+	return u.Path
+}
+
+// Query returns the query string values of the request URL.
+func (r Request) Query() url.Values {
+	u, err := r.parseURL()
+	if err != nil {
+		return nil
+	}
+	return u.Query()
+}
+
+func (r Request) parseURL() (*url.URL, error) {
+	raw := r.URL
+	if !strings.Contains(raw, "://") {
+		raw = "http://" + raw
+	}
+	return url.Parse(raw)
+}
+
+// curlArgFlags are the curl flags that take an argument, other than the ones
+// that are parsed into a Request.
+var curlArgFlags = map[string]bool{
+	"-A": true, "--user-agent": true,
+	"-b": true, "--cookie": true,
+	"-c": true, "--cookie-jar": true,
+	"-e": true, "--referer": true,
+	"-E": true, "--cert": true,
+	"-m": true, "--max-time": true,
+	"-o": true, "--output": true,
+	"-u": true, "--user": true,
+	"-w": true, "--write-out": true,
+	"-x": true, "--proxy": true,
+	"--cacert":          true,
+	"--connect-timeout": true,
+	"--resolve":         true,
+}
+
+// parseCurl parses an example curl command into a Request.  The command ends
+// at the first line that isn't continued with a backslash or an open quote,
+// so that any trailing prose in the example is ignored.  ErrNotCurl is
+// returned when the example doesn't start with the word curl, whatever else
+// it holds.
+func parseCurl(example string) (*Request, error) {
+	// prose can hold unbalanced quotes, as in "Here's how", so it is told
+	// apart before the command is split
+	if words := strings.Fields(example); len(words) == 0 || words[0] != "curl" {
+		return nil, ErrNotCurl
+	}
+	args, err := splitCommand(example)
+	if err != nil {
+		return nil, err
+	}
+
+	// --flag=value is equivalent to --flag value
+	var words []string
+	for _, arg := range args[1:] {
+		if n := strings.Index(arg, "="); strings.HasPrefix(arg, "--") && n > 0 {
+			words = append(words, arg[:n], arg[n+1:])
+		} else {
+			words = append(words, arg)
+		}
+	}
+
+	r := &Request{}
+	var data []string
+	get := false
+	for i := 0; i < len(words); i++ {
+		arg := words[i]
+		next := func() string {
+			if i+1 < len(words) {
+				i++
+				return words[i]
+			}
+			return ""
+		}
+
+		switch {
+		case arg == "-X" || arg == "--request":
+			r.Method = strings.ToUpper(next())
+		case arg == "-H" || arg == "--header":
+			h := next()
+			if n := strings.Index(h, ":"); n >= 0 {
+				r.Headers = append(r.Headers, Header{
+					Name:  strings.TrimSpace(h[:n]),
+					Value: strings.TrimSpace(h[n+1:]),
+				})
+			}
+		case arg == "-d" || strings.HasPrefix(arg, "--data"):
+			data = append(data, next())
+		case arg == "-G" || arg == "--get":
+			get = true
+		case arg == "-I" || arg == "--head":
+			if r.Method == "" {
+				r.Method = "HEAD"
+			}
+		case arg == "--url":
+			r.URL = next()
+		case curlArgFlags[arg]:
+			r.Flags = append(r.Flags, arg+" "+next())
+		case strings.HasPrefix(arg, "-") && len(arg) > 1:
+			r.Flags = append(r.Flags, arg)
+		case r.URL == "":
+			r.URL = arg
+		}
+	}
+
+	if r.URL == "" {
+		return r, ErrCurlNoURL
+	}
+
+	switch {
+	case get && len(data) > 0:
+		sep := "?"
+		if strings.Contains(r.URL, "?") {
+			sep = "&"
+		}
+		r.URL += sep + strings.Join(data, "&")
+	case len(data) > 0:
+		r.Body = strings.TrimSpace(strings.Join(data, "&"))
+	}
+
+	if r.Method == "" {
+		r.Method = "GET"
+		if r.Body != "" {
+			r.Method = "POST"
+		}
+	}
+	return r, nil
+}
+
+// splitCommand splits the first command in s into words, following the
+// quoting rules of a POSIX shell closely enough for example commands.
+func splitCommand(s string) ([]string, error) {
+	var (
+		args   []string
+		word   strings.Builder
+		inWord bool
+		quote  rune
+	)
+	runes := []rune(strings.TrimSpace(s))
+	for i := 0; i < len(runes); i++ {
+		c := runes[i]
+		switch {
+		case quote != 0:
+			if c == quote {
+				quote = 0
+			} else if c == '\\' && quote == '"' && i+1 < len(runes) && strings.ContainsRune(`"\$`+"`", runes[i+1]) {
+				i++
+				word.WriteRune(runes[i])
+			} else {
+				word.WriteRune(c)
+			}
+		case c == '\'' || c == '"':
+			quote = c
+			inWord = true
+		case c == '\\' && i+1 < len(runes):
+			i++
+			if runes[i] != '\n' {
+				word.WriteRune(runes[i])
+				inWord = true
+			}
+		case c == '\n' || c == ' ' || c == '\t':
+			if inWord {
+				args = append(args, word.String())
+				word.Reset()
+				inWord = false
+			}
+			if c == '\n' {
+				return args, nil
+			}
+		default:
+			word.WriteRune(c)
+			inWord = true
+		}
+	}
+	if quote != 0 {
+		return args, ErrCurlUnterminated
+	}
+	if inWord {
+		args = append(args, word.String())
+	}
+	return args, nil
+}
@@ -0,0 +1,154 @@
+// The MIT License (MIT)
+//
+// Copyright (c) 2015 Dylan Carney
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package main
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestSplitCommand(t *testing.T) {
+	tests := []struct {
+		name    string
+		in      string
+		want    []string
+		wantErr error
+	}{
+		{"words", "curl -v https://host/a", []string{"curl", "-v", "https://host/a"}, nil},
+		{"extra spaces", "  curl   -v\thttps://host/a  ", []string{"curl", "-v", "https://host/a"}, nil},
+		{"single quotes", `curl -d '{"a": "b c"}'`, []string{"curl", "-d", `{"a": "b c"}`}, nil},
+		{"double quotes", `curl -H "X-Id: 12 34"`, []string{"curl", "-H", "X-Id: 12 34"}, nil},
+		{"escapes in double quotes", `curl -d "a \"b\" \$c \n"`, []string{"curl", "-d", `a "b" $c \n`}, nil},
+		{"no escapes in single quotes", `curl -d 'a \' b`, []string{"curl", "-d", `a \`, "b"}, nil},
+		{"adjacent quotes", `curl -d 'a'"b"c`, []string{"curl", "-d", "abc"}, nil},
+		{"escaped space", `curl a\ b`, []string{"curl", "a b"}, nil},
+		{"empty quotes", `curl -d ''`, []string{"curl", "-d", ""}, nil},
+		{"continuation", "curl -v \\\n  https://host/a", []string{"curl", "-v", "https://host/a"}, nil},
+		{"quote spans lines", "curl -d '\n{}\n' https://host/a", []string{"curl", "-d", "\n{}\n", "https://host/a"}, nil},
+		{"ends at first line", "curl https://host/a\nreturns the thing", []string{"curl", "https://host/a"}, nil},
+		{"unterminated quote", `curl -d 'abc`, []string{"curl", "-d"}, ErrCurlUnterminated},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := splitCommand(tt.in)
+			if err != tt.wantErr {
+				t.Fatalf("splitCommand(%q) error = %v, want %v", tt.in, err, tt.wantErr)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("splitCommand(%q) = %q, want %q", tt.in, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseCurl(t *testing.T) {
+	tests := []struct {
+		name    string
+		in      string
+		want    *Request
+		wantErr error
+	}{
+		{
+			name: "get",
+			in:   "curl -v -k https://host/someapi/v1/foo",
+			want: &Request{Method: "GET", URL: "https://host/someapi/v1/foo", Flags: []string{"-v", "-k"}},
+		},
+		{
+			name: "headers and flags with arguments",
+			in:   `curl -H "X-Id: 12345" -u user:pass --max-time=5 https://host/a`,
+			want: &Request{
+				Method:  "GET",
+				URL:     "https://host/a",
+				Headers: []Header{{Name: "X-Id", Value: "12345"}},
+				Flags:   []string{"-u user:pass", "--max-time 5"},
+			},
+		},
+		{
+			name: "data implies post",
+			in:   "curl https://host/a \\\n  -d '{\"a\": 1}'",
+			want: &Request{Method: "POST", URL: "https://host/a", Body: `{"a": 1}`},
+		},
+		{
+			name: "several data are joined",
+			in:   "curl -d a=1 --data-urlencode b=2 https://host/a",
+			want: &Request{Method: "POST", URL: "https://host/a", Body: "a=1&b=2"},
+		},
+		{
+			name: "explicit method",
+			in:   "curl -X put -d x https://host/a",
+			want: &Request{Method: "PUT", URL: "https://host/a", Body: "x"},
+		},
+		{
+			name: "get with data",
+			in:   "curl -G -d limit=10 -d order=asc https://host/a",
+			want: &Request{Method: "GET", URL: "https://host/a?limit=10&order=asc"},
+		},
+		{
+			name: "get with data and a query",
+			in:   "curl --get --data limit=10 'https://host/a?q=x'",
+			want: &Request{Method: "GET", URL: "https://host/a?q=x&limit=10"},
+		},
+		{
+			name: "head",
+			in:   "curl -I https://host/a",
+			want: &Request{Method: "HEAD", URL: "https://host/a"},
+		},
+		{
+			name: "url flag",
+			in:   "curl --url https://host/a -v",
+			want: &Request{Method: "GET", URL: "https://host/a", Flags: []string{"-v"}},
+		},
+		{
+			name:    "not curl",
+			in:      "wget https://host/a",
+			wantErr: ErrNotCurl,
+		},
+		{
+			name:    "prose with an unbalanced quote",
+			in:      "Here's how you call it:\n  curl https://host/a",
+			wantErr: ErrNotCurl,
+		},
+		{
+			name:    "curl with an unbalanced quote",
+			in:      "curl -d 'abc https://host/a",
+			wantErr: ErrCurlUnterminated,
+		},
+		{
+			name:    "no url",
+			in:      "curl -v",
+			want:    &Request{Flags: []string{"-v"}},
+			wantErr: ErrCurlNoURL,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := parseCurl(tt.in)
+			if err != tt.wantErr {
+				t.Fatalf("parseCurl(%q) error = %v, want %v", tt.in, err, tt.wantErr)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("parseCurl(%q) = %+v, want %+v", tt.in, got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,119 @@
+// The MIT License (MIT)
+//
+// Copyright (c) 2015 Dylan Carney
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package main
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+)
+
+// ProblemModel is the name of the built-in ErrorModel for RFC 7807 problem
+// details, which can be referenced without declaring it, e.g.
+//
+//	Error Response 404 problem
+const ProblemModel = "problem"
+
+// An ErrorModel is an error response body shared by many endpoints.  It is
+// declared once in an apidoc-error(name) block, and referenced by name from
+// an Error Response, which then needn't repeat its example and fields.
+type ErrorModel struct {
+	Name        string
+	Description string
+	ContentType string
+	Content     string
+	Fields      []Parameter
+}
+
+// problemModel returns the RFC 7807 problem details model, with an example
+// for the given status code.
+func problemModel(code int) ErrorModel {
+	title := http.StatusText(code)
+	if title == "" {
+		title = "Error"
+	}
+	return ErrorModel{
+		Name:        ProblemModel,
+		Description: "An RFC 7807 problem details object.",
+		ContentType: "application/problem+json",
+		Content: fmt.Sprintf(`{
+  "type": "about:blank",
+  "title": %q,
+  "status": %d,
+  "detail": "A human-readable explanation of this occurrence of the problem.",
+  "instance": "/errors/%d"
+}`, title, code, code),
+		Fields: []Parameter{
+			{Name: "type", Type: "string", Format: "uri", Description: "A URI reference that identifies the problem type"},
+			{Name: "title", Type: "string", Description: "A short, human-readable summary of the problem type"},
+			{Name: "status", Type: "integer", Description: "The HTTP status code of the response"},
+			{Name: "detail", Type: "string", Description: "A human-readable explanation of this occurrence of the problem"},
+			{Name: "instance", Type: "string", Format: "uri", Description: "A URI reference that identifies this occurrence of the problem"},
+		},
+	}
+}
+
+// errorModel returns the ErrorModel of the given name for a response with the
+// given code.  Declared models take precedence over the built-in problem
+// model.
+func (doc *apiModel) errorModel(name string, code int) (ErrorModel, bool) {
+	for _, m := range doc.Errors {
+		if m.Name == name {
+			return m, true
+		}
+	}
+	if name == ProblemModel {
+		return problemModel(code), true
+	}
+	return ErrorModel{}, false
+}
+
+// resolveErrorModels expands the error responses that reference an ErrorModel
+// by filling in the parts that they don't document themselves.  References to
+// unknown models are left as they are, and reported by lint.
+func resolveErrorModels(doc *apiModel) {
+	for _, e := range doc.Endpoints {
+		for i := range e.ErrorResponses {
+			resp := &e.ErrorResponses[i]
+			if resp.Model == "" {
+				continue
+			}
+			m, ok := doc.errorModel(resp.Model, resp.Code)
+			if !ok {
+				continue
+			}
+			if strings.TrimSpace(resp.Content) == "" {
+				resp.Content = m.Content
+			}
+			if len(resp.Fields) == 0 {
+				resp.Fields = m.Fields
+			}
+			if resp.ContentType == "" {
+				resp.ContentType = m.ContentType
+			}
+			if resp.Description == "" {
+				resp.Description = m.Description
+			}
+		}
+	}
+}
@@ -18,16 +18,20 @@ import "net/http"
 // And this is a description of bar
 //
 // Success Response 200
-// 		{ "Message": "this shows a 200 response!" }
+//
+//	{ "Message": "this shows a 200 response!" }
 //
 // Error Response 400
-//    { "Message": "that's a bad request" }
+//
+//	{ "Message": "that's a bad request" }
 //
 // Error Response 401
-//    { "Message": "is your auth correct?" }
+//
+//	{ "Message": "is your auth correct?" }
 //
 // Example
-// 		curl -v -k -H "X-SomeCustomHeader: 12345" https://host/someapi/v1/foo/bar
+//
+//	curl -v -k -H "X-SomeCustomHeader: 12345" https://host/someapi/v1/foo/bar
 //
 // Foobar is an http.Handler that does things
 func Foobar(w http.ResponseWriter, r *http.Request) {
@@ -53,20 +57,23 @@ func Foobar(w http.ResponseWriter, r *http.Request) {
 // Bar is required, but it doesn't have a type
 //
 // Success Response 200
-// 		{ "Message": "this shows a 200 response!" }
+//
+//	{ "Message": "this shows a 200 response!" }
 //
 // Error Response 400
-//    { "Message": "that's a bad request" }
+//
+//	{ "Message": "that's a bad request" }
 //
 // Example
-// 		curl -v -H https://host/someapi/v1/fizz/buzz -d '
-//    {
-//      "attr": "foo",
-//      "attrB": "bar",
-//      "attrC": 89.45
-//		}'
 //
-// apidocs(buzz)
+//			curl -v https://host/someapi/v1/fizz/buzz -d '
+//	   {
+//	     "attr": "foo",
+//	     "attrB": "bar",
+//	     "attrC": 89.45
+//			}'
+//
+// apidoc(buzz)
 //
 // GET /someapi/v2/something/:fizz/:buzz
 //
@@ -74,13 +81,12 @@ func Foobar(w http.ResponseWriter, r *http.Request) {
 // This is a description of the endpoint and what it does.  There
 // are multiple sentences and lines that are included.
 //
-// A section
+// # A section
 //
 // This is still the description, since no other keyword has been found yet.
 // The section header above is just normal godoc behavior.
 //
-//
-// Another section
+// # Another section
 //
 // Even more description. This goes on and on and on until another keyword is
 // found, or until the end of the comment group.
@@ -99,10 +105,10 @@ func Foobar(w http.ResponseWriter, r *http.Request) {
 // And this is a description of buzz
 //
 // Success Response 200
-// 		{ "Message": "this shows a 200 response!" }
 //
-// Error Response 400
-//    { "Message": "that's a bad request" }
+//	{ "Message": "this shows a 200 response!" }
 //
+// Error Response 400
 //
+//	{ "Message": "that's a bad request" }
 func FizzBuzz(w http.ResponseWriter, r *http.Request) {}
@@ -0,0 +1,85 @@
+// The MIT License (MIT)
+//
+// Copyright (c) 2015 Dylan Carney
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+)
+
+// readRevision returns the contents of the file at path as of the git
+// revision rev.  It is read from the object store of the repository that
+// contains path, so the working tree is neither read nor changed.  An error
+// wrapping os.ErrNotExist is returned when the file isn't in that revision.
+func readRevision(rev, path string) ([]byte, error) {
+	dir, name := filepath.Split(path)
+	if dir == "" {
+		dir = "."
+	}
+
+	var stderr bytes.Buffer
+	cmd := exec.Command("git", "-C", dir, "show", rev+":./"+name)
+	cmd.Stderr = &stderr
+	src, err := cmd.Output()
+	if err != nil {
+		// git reports a bad revision and a missing path with the same exit
+		// status, and its messages depend on the locale, so tell them apart
+		// by checking the revision on its own.
+		if _, ok := err.(*exec.ExitError); ok && isRevision(dir, rev) {
+			return nil, fmt.Errorf("%s at %s: %w", path, rev, os.ErrNotExist)
+		}
+		msg := strings.TrimSpace(stderr.String())
+		if msg == "" {
+			msg = err.Error()
+		}
+		return nil, fmt.Errorf("could not read %s at %s: %s", path, rev, msg)
+	}
+	return src, nil
+}
+
+// isRevision reports whether rev names a commit in the repository that
+// contains dir.
+func isRevision(dir, rev string) bool {
+	return exec.Command("git", "-C", dir, "rev-parse", "--quiet", "--verify", rev+"^{commit}").Run() == nil
+}
+
+// listRevision returns the names of the files in dir as of the git revision
+// rev.
+func listRevision(rev, dir string) ([]string, error) {
+	var stderr bytes.Buffer
+	cmd := exec.Command("git", "-C", dir, "ls-tree", "--name-only", rev, "./")
+	cmd.Stderr = &stderr
+	out, err := cmd.Output()
+	if err != nil {
+		msg := strings.TrimSpace(stderr.String())
+		if msg == "" {
+			msg = err.Error()
+		}
+		return nil, fmt.Errorf("could not list %s at %s: %s", dir, rev, msg)
+	}
+	return strings.Fields(string(out)), nil
+}
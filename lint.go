@@ -0,0 +1,131 @@
+// The MIT License (MIT)
+//
+// Copyright (c) 2015 Dylan Carney
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package main
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+// lint checks the endpoints of a model against conventions that go beyond
+// what Endpoint.Validate requires, and returns an error for each endpoint
+// that doesn't follow them.  Unlike validation errors, these need the whole
+// model, since the auth schemes are declared in a package-level block and a
+// deprecated endpoint may be replaced by one in another file, and error
+// responses may reference error models declared elsewhere.  Deprecated
+// endpoints are also reported once their sunset date has passed, and routes
+// whose method doesn't fit what is documented for them.
+func lint(doc *apiModel) []error {
+	schemes := map[string]bool{}
+	if doc.API != nil {
+		for _, s := range doc.API.Auth {
+			schemes[s.Name] = true
+		}
+	}
+
+	names := map[string]bool{}
+	for _, e := range doc.Endpoints {
+		names[e.Name] = true
+	}
+
+	var errs []error
+	add := func(e *Endpoint, format string, args ...interface{}) {
+		errs = append(errs, fmt.Errorf("apidoc(%s): %s", e.Name, fmt.Sprintf(format, args...)))
+	}
+	for _, e := range doc.Endpoints {
+		if sunset, err := time.Parse(sunsetLayout, e.Sunset); err == nil && time.Now().After(sunset) {
+			add(e, "sunset date %s has passed, the endpoint should be removed", e.Sunset)
+		}
+		if e.Replacement != "" && !names[e.Replacement] {
+			add(e, "unknown replacement apidoc(%s)", e.Replacement)
+		}
+		for _, resp := range e.ErrorResponses {
+			if _, ok := doc.errorModel(resp.Model, resp.Code); resp.Model != "" && !ok {
+				add(e, "error response %d references unknown apidoc-error(%s)", resp.Code, resp.Model)
+			}
+		}
+		for _, route := range e.AllRoutes() {
+			for _, problem := range methodProblems(e, route.Method) {
+				add(e, "%s: %s", route, problem)
+			}
+		}
+
+		switch {
+		case e.Auth == nil:
+			add(e, "no Auth declared, use \"Auth %s\" for endpoints that don't require authentication", AuthNone)
+			continue
+		case !e.Auth.Required():
+			continue
+		case len(schemes) > 0 && !schemes[e.Auth.Scheme]:
+			add(e, "unknown auth scheme %q, it isn't declared in the apidoc-api block", e.Auth.Scheme)
+		}
+
+		// a suggested response is logged with the error, ready to be pasted
+		if !hasResponse(e, 401) {
+			add(e, "requires auth but has no 401 response, consider adding:\n\tError Response 401\n\t{\"error\": \"unauthorized\"}")
+		}
+		if len(e.Auth.Scopes) > 0 && !hasResponse(e, 403) {
+			add(e, "requires auth scopes but has no 403 response, consider adding:\n\tError Response 403\n\t{\"error\": \"forbidden\"}")
+		}
+	}
+	return errs
+}
+
+// methodProblems describes the ways in which what is documented for an
+// Endpoint doesn't fit the semantics of one of its methods.
+func methodProblems(e *Endpoint, method string) []string {
+	var problems []string
+	switch method {
+	case "GET", "HEAD":
+		if len(e.DataParams) > 0 {
+			problems = append(problems, "documents body parameters, but the request shouldn't have a body")
+		}
+		for _, r := range e.Requests {
+			if r.Method == method && r.Body != "" {
+				problems = append(problems, "has an example request with a body")
+				break
+			}
+		}
+	}
+	if method == "HEAD" && strings.TrimSpace(e.SuccessResponse.Content) != "" {
+		problems = append(problems, "documents a success response body, but HEAD responses don't have one")
+	}
+
+	switch code := e.SuccessResponse.Code; {
+	case code == 201 && method != "POST" && method != "PUT":
+		problems = append(problems, "documents 201 Created, which is for POST or PUT requests")
+	case code == 204 && strings.TrimSpace(e.SuccessResponse.Content) != "":
+		problems = append(problems, "documents 204 No Content with a response body")
+	}
+	return problems
+}
+
+func hasResponse(e *Endpoint, code int) bool {
+	for _, r := range e.ErrorResponses {
+		if r.Code == code {
+			return true
+		}
+	}
+	return false
+}
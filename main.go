@@ -23,6 +23,8 @@
 package main
 
 import (
+	"bytes"
+	"errors"
 	"flag"
 	"fmt"
 	"go/parser"
@@ -34,48 +36,237 @@ import (
 	"strings"
 )
 
-type renderFunc func(*Endpoint, io.Writer) error
+type renderFunc func(*apiModel, io.Writer) error
 
-func processFile(inputPath, outputPath string, render renderFunc) {
+func processFile(inputPath, outputPath string, doc *apiModel, render renderFunc) {
 	var err error
-	fmt.Printf("process file: %s, %s\n", inputPath, outputPath)
-	endpoints := loadFile(inputPath)
-	if len(endpoints) == 0 {
+	if !opts.check {
+		fmt.Printf("process file: %s, %s\n", inputPath, outputPath)
+	}
+	if len(doc.Endpoints) == 0 {
+		if err := retire(outputPath); err != nil {
+			log.Fatalf("could not remove output file: %s", err)
+		}
 		return
 	}
 
-	out, err := os.OpenFile(outputPath, os.O_WRONLY|os.O_CREATE, 0600)
-	if err != nil {
-		log.Fatalf("could not open output file: %s", err)
+	log.Printf("rendering template to: %s\n", outputPath)
+	var b bytes.Buffer
+	if err = render(doc, &b); err != nil {
+		log.Fatalf("could not generate apidoc: %s", err)
 	}
-	defer out.Close()
 
-	log.Printf("rendering template to: %s\n", outputPath)
-	for _, endpoint := range endpoints {
-		if err = render(endpoint, out); err != nil {
-			log.Fatalf("could not generate apidoc: %s", err)
+	if err := emit(outputPath, b.Bytes()); err != nil {
+		log.Fatalf("could not write output file: %s", err)
+	}
+}
+
+// emit writes generated output to path.  In -check mode nothing is written:
+// instead a unified diff between path and the output is printed, and the run
+// is marked as stale if they differ.
+func emit(path string, data []byte) error {
+	if !opts.check {
+		changed, err := writeFile(path, data)
+		if err == nil && !changed {
+			log.Printf("output file is unchanged: %s\n", path)
 		}
+		return err
+	}
+
+	existing, err := os.ReadFile(path)
+	if err != nil && !os.IsNotExist(err) {
+		return err
+	}
+	if diff := unifiedDiff(path, path+" (generated)", string(existing), string(data)); diff != "" {
+		log.Printf("output file is stale: %s\n", path)
+		fmt.Print(diff)
+		opts.stale = true
 	}
+	return nil
 }
 
-func loadFile(inputPath string) []*Endpoint {
-	fset := token.NewFileSet()
-	f, err := parser.ParseFile(fset, inputPath, nil, parser.ParseComments)
+// retire handles the output file of an input that no longer documents any
+// endpoints.  A file left over from an earlier run is removed, or, in -check
+// mode, reported as one that should no longer exist.
+func retire(path string) error {
+	existing, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		return nil
+	} else if err != nil {
+		return err
+	}
+
+	if !opts.check {
+		log.Printf("removing output file without endpoints: %s\n", path)
+		return os.Remove(path)
+	}
+	log.Printf("output file should no longer exist: %s\n", path)
+	fmt.Print(unifiedDiff(path, "/dev/null", string(existing), ""))
+	opts.stale = true
+	return nil
+}
+
+// reportLint logs the lint errors of doc when -lint is set.  In strict mode,
+// any lint error causes the process to exit.
+func reportLint(doc *apiModel) {
+	if !opts.lint {
+		return
+	}
+	errs := lint(doc)
+	for _, err := range errs {
+		log.Printf("lint: %s\n", err.Error())
+	}
+	if opts.strict && len(errs) > 0 {
+		log.Fatalf("found %d lint error(s)", len(errs))
+	}
+}
+
+// exitIfStale exits with a non-zero status if -check found stale output.
+func exitIfStale() {
+	if opts.stale {
+		log.Fatalf("generated output is stale, re-run apidoc to update it")
+	}
+}
+
+// writeFile atomically replaces the contents of path with data, by writing to
+// a temporary file in the same directory and renaming it over path.  Nothing
+// is written when path already holds data, so its mtime is left alone, and the
+// returned bool reports whether the file changed.  Written files have mode
+// 0644, like any other generated source.
+func writeFile(path string, data []byte) (bool, error) {
+	if existing, err := os.ReadFile(path); err == nil && bytes.Equal(existing, data) {
+		return false, nil
+	}
+
+	dir, name := filepath.Split(path)
+	if dir == "" {
+		dir = "."
+	}
+	tmp, err := os.CreateTemp(dir, "."+name+".tmp")
 	if err != nil {
-		log.Fatalf("error parsing file: %s", err)
+		return false, err
 	}
+	defer os.Remove(tmp.Name())
 
-	r := reader{
-		strict: opts.strict,
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return false, err
+	}
+	if err := tmp.Sync(); err != nil {
+		tmp.Close()
+		return false, err
+	}
+	if err := tmp.Close(); err != nil {
+		return false, err
+	}
+	if err := os.Chmod(tmp.Name(), 0644); err != nil {
+		return false, err
+	}
+	return true, os.Rename(tmp.Name(), path)
+}
+
+func loadFile(inputPath string) *apiModel {
+	var src []byte
+	if opts.rev != "" {
+		var err error
+		src, err = readRevision(opts.rev, inputPath)
+		if errors.Is(err, os.ErrNotExist) {
+			log.Printf("skipping src file: %s\n", err)
+			return &apiModel{}
+		}
+		if err != nil {
+			log.Fatalf("error reading file: %s", err)
+		}
+	}
+
+	r, err := parseFile(inputPath, src)
+	if err != nil {
+		log.Fatalf("error parsing file: %s", err)
+	}
+	if r.err != nil {
+		if opts.strict {
+			log.Fatalf("error reading apidoc in src file %s: %s\n", inputPath, r.err.Error())
+		}
+		log.Printf("error reading apidoc in src file %s: %s\n", inputPath, r.err.Error())
 	}
-	if err := r.readDocs(f.Comments); err != nil {
-		log.Printf("error reading apidoc in src file %s: %s\n", inputPath, err.Error())
+	for _, err := range r.invalid {
+		if opts.strict {
+			log.Fatalf("validation error: %s\n", err.Error())
+		}
+		log.Printf("validation error: %s\n", err.Error())
 	}
 
 	if len(r.endpoints) > 0 {
 		log.Printf("found %d apidoc(s) in src file: '%s'\n", len(r.endpoints), inputPath)
 	}
-	return r.endpoints
+	return r.model()
+}
+
+// parseFile reads the apidoc blocks of a Go source file.  The source is read
+// from inputPath unless src is non-nil.  Errors in the blocks are left in the
+// returned reader, rather than being logged.  Blocks that document a handler
+// without a method and URL get them from the handler's registration with a
+// mux, anywhere in the package.
+func parseFile(inputPath string, src []byte) (*reader, error) {
+	var source interface{}
+	if src != nil {
+		source = src
+	}
+
+	fset := token.NewFileSet()
+	f, err := parser.ParseFile(fset, inputPath, source, parser.ParseComments)
+	if err != nil {
+		return nil, err
+	}
+
+	r := &reader{
+		handlers:      handlerDocs(f),
+		registrations: packageRegistrations(inputPath, f),
+	}
+	r.err = r.readDocs(f.Comments)
+	return r, nil
+}
+
+// loadFiles loads the endpoints and tags documented in all of the given files.
+func loadFiles(paths []string) *apiModel {
+	var models []*apiModel
+	for _, path := range paths {
+		if !strings.HasSuffix(path, ".go") {
+			log.Fatalf("input file %s doesn't have .go extension", path)
+		}
+		models = append(models, loadFile(path))
+	}
+	return mergeModels(models)
+}
+
+// mergeModels combines several models into one.  When the API, a tag or an
+// error model is described more than once, the first description wins.  The
+// error responses that reference an error model are expanded once all the
+// models are known, since they are typically declared in another file.
+func mergeModels(models []*apiModel) *apiModel {
+	doc := &apiModel{}
+	seen := map[string]bool{}
+	seenErrors := map[string]bool{}
+	for _, m := range models {
+		if doc.API == nil {
+			doc.API = m.API
+		}
+		for _, tag := range m.Tags {
+			if !seen[tag.Name] {
+				seen[tag.Name] = true
+				doc.Tags = append(doc.Tags, tag)
+			}
+		}
+		for _, em := range m.Errors {
+			if !seenErrors[em.Name] {
+				seenErrors[em.Name] = true
+				doc.Errors = append(doc.Errors, em)
+			}
+		}
+		doc.Endpoints = append(doc.Endpoints, m.Endpoints...)
+	}
+	resolveErrorModels(doc)
+	return doc
 }
 
 func deriveOutputPath(inputPath, extension string) string {
@@ -90,21 +281,51 @@ func deriveOutputPath(inputPath, extension string) string {
 
 var opts struct {
 	strict bool
+	verbs  verbList
 	output string
 	format int
+	theme  string
+	check  bool
+	rev    string
+	lint   bool
+
+	// stale is set in check mode when an output file is out of date
+	stale bool
 }
 
 func main() {
 
+	log.SetFlags(0)
+	log.SetPrefix("apidoc: ")
+
+	if len(os.Args) > 1 {
+		switch os.Args[1] {
+		case "gen-client":
+			genClient(os.Args[2:])
+			return
+		case "gen-ts":
+			genTypeScript(os.Args[2:])
+			return
+		case "serve":
+			serve(os.Args[2:])
+			return
+		case "diff":
+			diffCommand(os.Args[2:])
+			return
+		}
+	}
+
 	var format string
-	flag.StringVar(&opts.output, "out", "", "Name of the output file to use. If not specified, the output file name will be based on the package and input file name.")
+	flag.StringVar(&opts.output, "out", "", "Name of the output file to use, combining the docs of all input files. If not specified, each input file gets its own output file, named after the package and input file name.")
 	flag.BoolVar(&opts.strict, "strict", false, "Enables validation checks on each apidoc comment block. When strict is true, any validation error causes the process to exit.")
-	flag.StringVar(&format, "format", "markdown", "Specifies the format to render the docs in [markdown|html]. Defaults to markdown.")
+	flag.StringVar(&format, "format", "markdown", "Specifies the format to render the docs in [markdown|html|http|json|site]. json writes the model read by the diff command, and site writes a multi-page HTML site into the -out directory. Defaults to markdown.")
+	flag.StringVar(&opts.rev, "rev", "", "Reads the input files as of a git revision (e.g. a release tag) from the repository's object store, instead of from the working tree.")
+	flag.Var(&opts.verbs, "verbs", "Comma-separated list of HTTP methods to recognize besides the standard ones, e.g. PROPFIND,REPORT,QUERY.")
+	flag.BoolVar(&opts.lint, "lint", false, "Reports endpoints that don't follow the documentation conventions, e.g. that don't declare their Auth, that require auth but document no 401 response, or whose method doesn't fit what is documented, such as a body on GET. In strict mode, any lint error causes the process to exit.")
+	flag.BoolVar(&opts.check, "check", false, "Renders the docs without writing them, printing a diff and exiting non-zero if the existing output files are out of date.")
+	flag.StringVar(&opts.theme, "theme", "light", "Specifies the color theme of html output [light|dark|auto]. auto follows the reader's system setting. Defaults to light.")
 	flag.Parse()
 
-	log.SetFlags(0)
-	log.SetPrefix("apidoc: ")
-
 	var render renderFunc
 	var ext string
 	switch format {
@@ -114,23 +335,59 @@ func main() {
 	case "markdown":
 		render = RenderMarkdown
 		ext = "md"
+	case "http":
+		render = RenderHTTPFile
+		ext = "http"
+	case "json":
+		render = RenderJSON
+		ext = "json"
+	case "site":
 	default:
-		log.Fatalf("invalid format '%s'. Form can be: [markdown|html]", format)
+		log.Fatalf("invalid format '%s'. Form can be: [markdown|html|http|json|site]", format)
+	}
+
+	switch opts.theme {
+	case "light", "dark", "auto":
+	default:
+		log.Fatalf("invalid theme '%s'. Theme can be: [light|dark|auto]", opts.theme)
 	}
 
 	if opts.strict {
 		log.Println("strict mode")
 	}
 
+	if format == "site" {
+		if opts.output == "" {
+			log.Fatalf("the site format requires an -out directory")
+		}
+		doc := loadFiles(flag.Args())
+		reportLint(doc)
+		if err := writeSite(opts.output, doc); err != nil {
+			log.Fatalf("could not generate site: %s", err)
+		}
+		exitIfStale()
+		return
+	}
+
+	// every input file is loaded up front, so that tags described in one file
+	// (typically doc.go) apply to the endpoints of the others
+	var models []*apiModel
 	for _, path := range flag.Args() {
 		if !strings.HasSuffix(path, ".go") {
 			panic(fmt.Errorf("input file %s doesn't have .go extension", path))
 		}
+		models = append(models, loadFile(path))
+	}
+	all := mergeModels(models)
+	reportLint(all)
 
-		if opts.output == "" {
-			processFile(path, deriveOutputPath(path, ext), render)
-		} else {
-			processFile(path, opts.output, render)
+	if opts.output != "" {
+		processFile(strings.Join(flag.Args(), ", "), opts.output, all, render)
+	} else {
+		for i, path := range flag.Args() {
+			doc := &apiModel{Tags: all.Tags, Errors: all.Errors, Endpoints: models[i].Endpoints}
+			processFile(path, deriveOutputPath(path, ext), doc, render)
 		}
 	}
+	exitIfStale()
 }
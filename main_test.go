@@ -0,0 +1,60 @@
+// The MIT License (MIT)
+//
+// Copyright (c) 2015 Dylan Carney
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestRetire(t *testing.T) {
+	defer func(check, stale bool) { opts.check, opts.stale = check, stale }(opts.check, opts.stale)
+
+	path := filepath.Join(t.TempDir(), "h_apidoc.md")
+	if err := retire(path); err != nil {
+		t.Fatalf("retire of a missing file: %v", err)
+	}
+
+	if err := os.WriteFile(path, []byte("# old\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	opts.check, opts.stale = true, false
+	if err := retire(path); err != nil {
+		t.Fatalf("retire in check mode: %v", err)
+	}
+	if !opts.stale {
+		t.Error("retire in check mode: output not reported as stale")
+	}
+	if _, err := os.Stat(path); err != nil {
+		t.Errorf("retire in check mode removed the file: %v", err)
+	}
+
+	opts.check = false
+	if err := retire(path); err != nil {
+		t.Fatalf("retire: %v", err)
+	}
+	if _, err := os.Stat(path); !os.IsNotExist(err) {
+		t.Errorf("retire left the file behind: %v", err)
+	}
+}
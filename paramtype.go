@@ -0,0 +1,215 @@
+// The MIT License (MIT)
+//
+// Copyright (c) 2015 Dylan Carney
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package main
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// The kinds of ParamType.
+const (
+	KindString  = "string"
+	KindInteger = "integer"
+	KindNumber  = "number"
+	KindBoolean = "boolean"
+	KindObject  = "object"
+	KindArray   = "array"
+	KindMap     = "map"
+	KindNamed   = "named"
+)
+
+// primitiveKinds maps the spellings of the primitive types onto their kinds,
+// so that e.g. "int", "integer" and "numeric" are documented consistently.
+var primitiveKinds = map[string]string{
+	"string":  KindString,
+	"str":     KindString,
+	"int":     KindInteger,
+	"integer": KindInteger,
+	"long":    KindInteger,
+	"number":  KindNumber,
+	"numeric": KindNumber,
+	"float":   KindNumber,
+	"double":  KindNumber,
+	"decimal": KindNumber,
+	"bool":    KindBoolean,
+	"boolean": KindBoolean,
+	"object":  KindObject,
+	"map":     KindObject,
+}
+
+// a named Go type, e.g. "User" or "models.User"
+var namedTypeRx = regexp.MustCompile(`^(?:[A-Za-z_]\w*\.)?[A-Z]\w*$`)
+
+// A ParamType is the parsed form of the type of a Parameter.  Types follow
+// the grammar:
+//
+//	type  = base [ "?" ]
+//	base  = primitive | "object" | "array of" type | "map of" type | Name
+//
+// where a primitive is one of string, integer, number or boolean (or one of
+// their other spellings, such as int or numeric), and Name is a named Go
+// type, such as User or models.User.  A trailing "?" marks the whole type
+// as nullable, and the element type of an array may be plural, as in
+// "array of strings".
+type ParamType struct {
+	Kind     string
+	Elem     *ParamType // the element type of an array or map
+	Name     string     // the name of a named type
+	Nullable bool
+}
+
+// An UnknownTypeError reports a parameter type that doesn't follow the type
+// grammar.
+type UnknownTypeError struct {
+	Param string
+	Type  string
+}
+
+func (e UnknownTypeError) Error() string {
+	return fmt.Sprintf("apidoc: unknown type %q of param %s", e.Type, e.Param)
+}
+
+// parseParamType parses a parameter type.  The empty type is a string.
+func parseParamType(typ string) (*ParamType, bool) {
+	typ = strings.Join(strings.Fields(typ), " ")
+	if typ == "" {
+		return &ParamType{Kind: KindString}, true
+	}
+
+	nullable := strings.HasSuffix(typ, "?")
+	typ = strings.TrimSpace(strings.TrimSuffix(typ, "?"))
+
+	var t *ParamType
+	lower := strings.ToLower(typ)
+	switch {
+	case primitiveKinds[lower] != "":
+		t = &ParamType{Kind: primitiveKinds[lower]}
+	case strings.HasPrefix(lower, "array of "), strings.HasPrefix(lower, "map of "):
+		kind, elem := KindArray, typ[len("array of "):]
+		if strings.HasPrefix(lower, "map of ") {
+			kind, elem = KindMap, typ[len("map of "):]
+		}
+		e, ok := parseParamType(elem)
+		if !ok {
+			if e, ok = parseParamType(strings.TrimSuffix(elem, "s")); !ok || e.Kind == KindNamed {
+				return nil, false
+			}
+		}
+		t = &ParamType{Kind: kind, Elem: e}
+	case namedTypeRx.MatchString(typ):
+		t = &ParamType{Kind: KindNamed, Name: typ}
+	default:
+		return nil, false
+	}
+	t.Nullable = nullable
+	return t, true
+}
+
+// String returns the canonical spelling of the type.
+func (t *ParamType) String() string {
+	var s string
+	switch t.Kind {
+	case KindArray:
+		s = "array of " + t.Elem.String()
+	case KindMap:
+		s = "map of " + t.Elem.String()
+	case KindNamed:
+		s = t.Name
+	default:
+		s = t.Kind
+	}
+	if t.Nullable {
+		s += "?"
+	}
+	return s
+}
+
+// goType returns the Go type of values of the type.  Nullable types aren't
+// made pointers, since an omitted zero value serves the same purpose in the
+// generated client, and named types are left as interface{}, since they
+// aren't defined there.
+func (t *ParamType) goType() string {
+	switch t.Kind {
+	case KindString:
+		return "string"
+	case KindInteger:
+		return "int"
+	case KindNumber:
+		return "float64"
+	case KindBoolean:
+		return "bool"
+	case KindObject:
+		return "map[string]interface{}"
+	case KindArray:
+		return "[]" + t.Elem.goType()
+	case KindMap:
+		return "map[string]" + t.Elem.goType()
+	}
+	return "interface{}"
+}
+
+// tsType returns the TypeScript type of values of the type.
+func (t *ParamType) tsType() string {
+	var s string
+	switch t.Kind {
+	case KindString:
+		s = "string"
+	case KindInteger, KindNumber:
+		s = "number"
+	case KindBoolean:
+		s = "boolean"
+	case KindObject:
+		s = "Record<string, unknown>"
+	case KindArray:
+		s = t.Elem.tsType()
+		if strings.Contains(s, " ") {
+			s = "(" + s + ")"
+		}
+		s += "[]"
+	case KindMap:
+		s = "Record<string, " + t.Elem.tsType() + ">"
+	default:
+		s = "unknown"
+	}
+	if t.Nullable {
+		s += " | null"
+	}
+	return s
+}
+
+// zeroValue returns a JSON placeholder value for the type.
+func (t *ParamType) zeroValue() string {
+	switch t.Kind {
+	case KindString:
+		return `""`
+	case KindInteger, KindNumber:
+		return "0"
+	case KindBoolean:
+		return "false"
+	case KindArray:
+		return "[]"
+	}
+	return "{}"
+}
@@ -0,0 +1,81 @@
+// The MIT License (MIT)
+//
+// Copyright (c) 2015 Dylan Carney
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package main
+
+import "testing"
+
+func TestParseParamType(t *testing.T) {
+	tests := []struct {
+		in     string
+		want   string // the canonical spelling, or "" if the type is invalid
+		goType string
+		tsType string
+	}{
+		{"", "string", "string", "string"},
+		{"str", "string", "string", "string"},
+		{"int", "integer", "int", "number"},
+		{"Numeric", "number", "float64", "number"},
+		{"bool", "boolean", "bool", "boolean"},
+		{"map", "object", "map[string]interface{}", "Record<string, unknown>"},
+		{"integer?", "integer?", "int", "number | null"},
+		{"integer ?", "integer?", "int", "number | null"},
+		{"array of int", "array of integer", "[]int", "number[]"},
+		{"array of strings", "array of string", "[]string", "string[]"},
+		{"Array  Of  integers", "array of integer", "[]int", "number[]"},
+		{"array of array of bool", "array of array of boolean", "[][]bool", "boolean[][]"},
+		{"array of string?", "array of string?", "[]string", "string[] | null"},
+		{"map of numbers", "map of number", "map[string]float64", "Record<string, number>"},
+		{"User", "User", "interface{}", "unknown"},
+		{"models.User", "models.User", "interface{}", "unknown"},
+		{"array of User", "array of User", "[]interface{}", "unknown[]"},
+		{"array of Users", "array of Users", "[]interface{}", "unknown[]"},
+		{"user", "", "", ""},
+		{"array of", "", "", ""},
+		{"list of strings", "", "", ""},
+		{"models.user", "", "", ""},
+	}
+	for _, tt := range tests {
+		got, ok := parseParamType(tt.in)
+		if tt.want == "" {
+			if ok {
+				t.Errorf("parseParamType(%q) = %s, want an invalid type", tt.in, got)
+			}
+			continue
+		}
+		if !ok {
+			t.Errorf("parseParamType(%q) is invalid, want %s", tt.in, tt.want)
+			continue
+		}
+		if got.String() != tt.want || got.goType() != tt.goType || got.tsType() != tt.tsType {
+			t.Errorf("parseParamType(%q) = %s, %s, %s, want %s, %s, %s", tt.in, got, got.goType(), got.tsType(), tt.want, tt.goType, tt.tsType)
+		}
+	}
+}
+
+func TestParamTypeNullable(t *testing.T) {
+	for in, want := range map[string]bool{"string": false, "string?": true, "array of string?": true, "User?": true} {
+		if got, ok := parseParamType(in); !ok || got.Nullable != want {
+			t.Errorf("parseParamType(%q).Nullable = %v, want %v", in, got.Nullable, want)
+		}
+	}
+}
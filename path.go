@@ -0,0 +1,106 @@
+// The MIT License (MIT)
+//
+// Copyright (c) 2015 Dylan Carney
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package main
+
+import (
+	"regexp"
+	"strings"
+)
+
+// A pathSegment is one "/"-separated segment of a URLTemplate.  Params can be
+// written in any of the syntaxes that routers use:
+//
+//	:name          the original apidoc syntax
+//	{name}         Go 1.22 http.ServeMux and OpenAPI
+//	{name...}      Go 1.22 wildcard, matching the rest of the path
+//	{$}            Go 1.22 anchor, matching only the end of a path ending in "/"
+//	{name:[0-9]+}  gorilla/mux, where the param must match the expression
+//
+// Any other segment is a literal.  Since the URLTemplate is split on "/", an
+// expression can't contain a "/".
+type pathSegment struct {
+	Literal  string // the text of a literal segment
+	Param    string // the name of a param segment
+	Pattern  string // the expression that the value of a param must match
+	Wildcard bool   // whether the param matches the rest of the path
+	End      bool   // whether the segment is {$}
+}
+
+// parseSegment parses one segment of a URLTemplate.
+func parseSegment(s string) pathSegment {
+	switch {
+	case strings.HasPrefix(s, ":") && len(s) > 1:
+		return pathSegment{Param: s[1:]}
+	case !strings.HasPrefix(s, "{") || !strings.HasSuffix(s, "}") || len(s) < 3:
+		return pathSegment{Literal: s}
+	}
+
+	name := s[1 : len(s)-1]
+	switch {
+	case name == "$":
+		return pathSegment{End: true}
+	case strings.HasSuffix(name, "..."):
+		return pathSegment{Param: strings.TrimSuffix(name, "..."), Wildcard: true}
+	}
+	if i := strings.Index(name, ":"); i >= 0 {
+		return pathSegment{Param: name[:i], Pattern: name[i+1:]}
+	}
+	return pathSegment{Param: name}
+}
+
+// pathSegments parses each of the "/"-separated segments of a URLTemplate, so
+// that the result lines up with strings.Split(urlTemplate, "/").
+func pathSegments(urlTemplate string) []pathSegment {
+	splits := strings.Split(urlTemplate, "/")
+	segs := make([]pathSegment, len(splits))
+	for i, split := range splits {
+		segs[i] = parseSegment(split)
+	}
+	return segs
+}
+
+// compile returns the expression that the values of a param segment must
+// match, anchored to the whole value, or nil if there is none.
+func (s pathSegment) compile() (*regexp.Regexp, error) {
+	if s.Pattern == "" {
+		return nil, nil
+	}
+	return regexp.Compile("^(?:" + s.Pattern + ")$")
+}
+
+// canonicalPath returns a URLTemplate with every param written as {name}, so
+// that templates that differ only in their param syntax compare equal.
+func canonicalPath(urlTemplate string) string {
+	splits := strings.Split(urlTemplate, "/")
+	for i, seg := range pathSegments(urlTemplate) {
+		switch {
+		case seg.End:
+			splits[i] = "{$}"
+		case seg.Wildcard:
+			splits[i] = "{" + seg.Param + "...}"
+		case seg.Param != "":
+			splits[i] = "{" + seg.Param + "}"
+		}
+	}
+	return strings.Join(splits, "/")
+}
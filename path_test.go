@@ -0,0 +1,78 @@
+// The MIT License (MIT)
+//
+// Copyright (c) 2015 Dylan Carney
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package main
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestMatchTemplate(t *testing.T) {
+	tests := []struct {
+		template, path string
+		want           map[string]string // nil if the path doesn't match
+	}{
+		{"/users", "/users", map[string]string{}},
+		{"/users", "/users/", map[string]string{}},
+		{"/users/", "/users", map[string]string{}},
+		{"/users", "/items", nil},
+		{"/users", "/users/1", nil},
+		{"/users/:id", "/users/1", map[string]string{"id": "1"}},
+		{"/users/{id}", "/users/1", map[string]string{"id": "1"}},
+		{"/users/{id}", "/users/", nil},
+		{"/users/{id}/posts/{post}", "/users/1/posts/2", map[string]string{"id": "1", "post": "2"}},
+		{"/users/{id:[0-9]+}", "/users/42", map[string]string{"id": "42"}},
+		{"/users/{id:[0-9]+}", "/users/abc", nil},
+		{"/users/{id:[0-9]+}", "/users/42abc", nil},
+		{"/files/{path...}", "/files/a", map[string]string{"path": "a"}},
+		{"/files/{path...}", "/files/a/b/c.txt", map[string]string{"path": "a/b/c.txt"}},
+		{"/files/{path...}", "/file", nil},
+		{"/{$}", "/", map[string]string{}},
+		{"/{$}", "/users", nil},
+		{"/users/{$}", "/users/", map[string]string{}},
+		{"/users/{$}", "/users", nil},
+		{"/users/{$}", "/users/1", nil},
+	}
+	for _, tt := range tests {
+		got, ok := matchTemplate(tt.template, tt.path)
+		if ok != (tt.want != nil) || (ok && !reflect.DeepEqual(got, tt.want)) {
+			t.Errorf("matchTemplate(%q, %q) = %v, %v, want %v", tt.template, tt.path, got, ok, tt.want)
+		}
+	}
+}
+
+func TestCanonicalPath(t *testing.T) {
+	tests := map[string]string{
+		"/users/:id":             "/users/{id}",
+		"/users/{id}":            "/users/{id}",
+		"/users/{id:[0-9]+}":     "/users/{id}",
+		"/files/{path...}":       "/files/{path...}",
+		"/{$}":                   "/{$}",
+		"/users/:id/posts/{pid}": "/users/{id}/posts/{pid}",
+	}
+	for in, want := range tests {
+		if got := canonicalPath(in); got != want {
+			t.Errorf("canonicalPath(%q) = %q, want %q", in, got, want)
+		}
+	}
+}
@@ -25,10 +25,10 @@ package main
 import (
 	"fmt"
 	"go/ast"
-	"log"
 	"regexp"
 	"strconv"
 	"strings"
+	"time"
 )
 
 const (
@@ -36,30 +36,144 @@ const (
 	KWNotes           = "Notes"
 	KWSuccessResponse = "Success Response"
 	KWErrorResponse   = "Error Response"
+	KWResponseField   = "Response Field"
 	KWExample         = "Example"
 	KWParameter       = "Parameter"
+	KWBodyParameter   = "Body Parameter"
+	KWTags            = "Tags"
+	KWVersion         = "Version"
+	KWServer          = "Server"
+	KWContact         = "Contact"
+	KWLicense         = "License"
+	KWAuth            = "Auth"
+	KWDeprecated      = "Deprecated"
 	KWMethod          = "Method"
+	KWContentType     = "Content Type"
 	KWNone            = "(none)"
 )
 
 var (
-	apidocMarker    = `(apidoc)\(([^)]+)\):?`                           // apidoc(name), name of at least 1 char
+	apidocMarker    = `(apidoc(?:-tag|-api|-error)?)\(([^)]+)\):?`      // apidoc(name), apidoc-tag(name), apidoc-api(title) or apidoc-error(name), name of at least 1 char
 	apidocMarkerRx  = regexp.MustCompile(`^[ \t]*` + apidocMarker)      // the marker at text start
 	apidocCommentRx = regexp.MustCompile(`^/[/*][ \t]*` + apidocMarker) // the marker at comment start
 
 	// Example: GET /some/path/:foo
-	httpVerbRx = regexp.MustCompile(`(GET|PUT|POST|DELETE|HEAD|OPTIONS|TRACE|CONNECT|PATCH)\s+(/.*)`)
+	httpVerbRx = verbRx(standardVerbs)
 
-	// Parameter docs follow the pattern:   name [, "required"] [, type]
+	// Example: /someapi/v1/hello, /someapi/v2beta/hello
+	versionSegmentRx = regexp.MustCompile(`^v\d+(?:(?:alpha|beta)\d*)?$`)
+
+	// Parameter docs follow the pattern:   name [, "required"] [, type] [, constraint=value ...]
 	// Examples:
 	//    foobar
 	//    foobar, string
 	//    foobar, required, string
 	//    foobar, required
 	//    foobar, required, array of strings
-	parameterRx = regexp.MustCompile(`^([\w-]+)(?:\s*,\s*(required))?(?:\s*,\s*([\w\s]+))?$`)
+	//    foobar, map of integer?
+	//    foobar, models.User
+	//    limit, integer, default=10, min=1, max=100
+	//    order, string, enum=asc|desc
+	parameterRx = regexp.MustCompile(`^([\w-]+)(?:\s*,\s*(required))?(?:\s*,\s*([\w\s.?]+))?$`)
+
+	// Response fields follow the same pattern, with a dotted path to the field
+	// in place of the name, where "[]" marks the elements of an array.
+	// Examples:
+	//    total, integer
+	//    items[].id, required, string
+	responseFieldRx = regexp.MustCompile(`^([\w-]+(?:\[\])*(?:\.[\w-]+(?:\[\])*)*)(?:\s*,\s*(required))?(?:\s*,\s*([\w\s.?]+))?$`)
+
+	// An Auth line names a scheme, and any scopes it requires.  Only a line
+	// of this exact shape is an Auth keyword, so that prose starting with
+	// "Auth" isn't taken for one.
+	// Examples:
+	//    Auth none
+	//    Auth bearer scope:users.read scope:users.write
+	authLineRx = regexp.MustCompile(`^Auth[ \t]+(?:none|\w+(?:[ \t]+scope:\S+)*)[ \t]*$`)
+
+	// A Tags line is the keyword alone, with the tags on the lines after it,
+	// or followed by a comma-separated list of single-word tags, so that
+	// prose starting with "Tags" isn't taken for one.
+	// Examples:
+	//    Tags users
+	//    Tags users, admin
+	tagsLineRx = regexp.MustCompile(`^Tags(?:[ \t]+[^\s,]+(?:[ \t]*,[ \t]*[^\s,]+)*[ \t]*,?)?[ \t]*$`)
+
+	// A Version line gives a single version, such as "v2" or "2", so that
+	// prose starting with "Version" isn't taken for one.
+	// Examples:
+	//    Version v2
+	//    Version 2beta1
+	versionLineRx = regexp.MustCompile(`^Version[ \t]+v?\d+\S*[ \t]*$`)
+
+	// A Deprecated line is the keyword alone, or followed by options.  The
+	// lines after it are a note about the deprecation.
+	// Examples:
+	//    Deprecated
+	//    Deprecated since:2026-01-01 replacement:get-user-v2 sunset:2026-12-31
+	deprecatedLineRx = regexp.MustCompile(`^Deprecated(?:[ \t]+\w+:\S*)*[ \t]*$`)
+
+	// The lines of an apidoc-api block only start a keyword when they have
+	// its exact shape, so that prose in the introduction, like "Servers in
+	// the EU are..." or "Contact your account manager...", stays in it.
+	// Examples:
+	//    Description
+	//    Description: The Widgets API
+	//    Server https://api.example.com
+	//    Server Production https://api.example.com/v1
+	//    Contact API Team <api@example.com>
+	//    License Apache-2.0 https://www.apache.org/licenses/LICENSE-2.0
+	apiDescriptionLineRx = regexp.MustCompile(`^Description(?::.*|[ \t]*)$`)
+	apiServerLineRx      = regexp.MustCompile(`^Server(?:[ \t]+\S+)*[ \t]+(?:\w+://\S+|/\S*)[ \t]*$`)
+	apiContactLineRx     = regexp.MustCompile(`^Contact(?:[ \t]+[^\s<>]+)*[ \t]+<?(?:[^\s<>@]+@[^\s<>@]+\.[^\s<>@.]+|\w+://[^\s<>]+)>?[ \t]*$`)
+	apiLicenseLineRx     = regexp.MustCompile(`^License[ \t]+[\w.+-]+(?:[ \t]+\w+://\S+)?[ \t]*$`)
+
+	// the start of each constraint of a parameter.  A constraint value extends
+	// to the start of the next one, so that patterns can contain commas.
+	constraintRx = regexp.MustCompile(`,\s*(default|enum|min|max|pattern|format)=`)
 )
 
+// standardVerbs are the HTTP methods that are recognized without -verbs.
+var standardVerbs = []string{"GET", "PUT", "POST", "DELETE", "HEAD", "OPTIONS", "TRACE", "CONNECT", "PATCH"}
+
+// customVerbRx matches a valid name for an extra HTTP method.
+var customVerbRx = regexp.MustCompile(`^[A-Z][A-Z0-9_-]*$`)
+
+// verbRx returns the regexp for a method line using any of the given verbs.
+func verbRx(verbs []string) *regexp.Regexp {
+	quoted := make([]string, len(verbs))
+	for i, v := range verbs {
+		quoted[i] = regexp.QuoteMeta(v)
+	}
+	return regexp.MustCompile(`(` + strings.Join(quoted, "|") + `)\s+(/.*)`)
+}
+
+// verbList is the value of the -verbs flag: a comma-separated list of HTTP
+// methods, such as the WebDAV PROPFIND or REPORT, that are recognized besides
+// the standard ones.
+type verbList []string
+
+func (v *verbList) String() string {
+	if v == nil {
+		return ""
+	}
+	return strings.Join(*v, ",")
+}
+
+func (v *verbList) Set(s string) error {
+	for _, verb := range strings.Split(s, ",") {
+		if verb = strings.TrimSpace(verb); verb == "" {
+			continue
+		}
+		if !customVerbRx.MatchString(verb) {
+			return fmt.Errorf("invalid HTTP method %q, methods are upper case", verb)
+		}
+		*v = append(*v, verb)
+	}
+	httpVerbRx = verbRx(append(append([]string{}, standardVerbs...), *v...))
+	return nil
+}
+
 // TODO: change this to a regexp?
 func startsWithKeyword(str string) string {
 
@@ -70,12 +184,24 @@ func startsWithKeyword(str string) string {
 		return KWSuccessResponse
 	case strings.HasPrefix(str, KWErrorResponse):
 		return KWErrorResponse
+	case strings.HasPrefix(str, KWResponseField):
+		return KWResponseField
 	case strings.HasPrefix(str, KWExample):
 		return KWExample
 	case strings.HasPrefix(str, KWParameter):
 		return KWParameter
+	case strings.HasPrefix(str, KWBodyParameter):
+		return KWBodyParameter
 	case strings.HasPrefix(str, KWNotes):
 		return KWNotes
+	case tagsLineRx.MatchString(str):
+		return KWTags
+	case authLineRx.MatchString(str):
+		return KWAuth
+	case deprecatedLineRx.MatchString(str):
+		return KWDeprecated
+	case versionLineRx.MatchString(str):
+		return KWVersion
 	case httpVerbRx.MatchString(str):
 		return KWMethod
 	}
@@ -108,25 +234,33 @@ func parseKeyword(e *Endpoint, kw string, lines []string) error {
 	case KWMethod:
 		matches := httpVerbRx.FindStringSubmatch(lines[0])
 		if len(matches) > 0 {
-			e.Method = matches[1]
-			e.URLTemplate = matches[2]
+			if len(e.Routes) == 0 {
+				e.Method = matches[1]
+				e.URLTemplate = matches[2]
+			}
+			e.Routes = append(e.Routes, Route{Method: matches[1], URLTemplate: matches[2]})
 		}
 	case KWDescription:
 		lines = stripKeyword(KWDescription, lines)
 		e.Description = strings.Join(lines, "\n")
 	case KWParameter:
 		lines = stripKeyword(KWParameter, lines)
-		matches := parameterRx.FindStringSubmatch(lines[0])
-		if len(matches) > 0 {
-			p := Parameter{
-				Name:        matches[1],
-				Required:    matches[2] == "required",
-				Type:        matches[3],
-				Description: strings.Join(lines[1:], " "),
-			}
-
+		if p, ok := parseParameter(parameterRx, lines); ok {
 			e.URLParams = append(e.URLParams, p)
 		}
+	case KWBodyParameter:
+		lines = stripKeyword(KWBodyParameter, lines)
+		if p, ok := parseParameter(parameterRx, lines); ok {
+			e.DataParams = append(e.DataParams, p)
+		}
+	case KWResponseField:
+		lines = stripKeyword(KWResponseField, lines)
+		if e.lastResponse == nil {
+			return fmt.Errorf("%s must follow a %s or %s", KWResponseField, KWSuccessResponse, KWErrorResponse)
+		}
+		if f, ok := parseParameter(responseFieldRx, lines); ok {
+			e.lastResponse.Fields = append(e.lastResponse.Fields, f)
+		}
 
 	case KWSuccessResponse:
 		lines = stripKeyword(KWSuccessResponse, lines)
@@ -138,9 +272,14 @@ func parseKeyword(e *Endpoint, kw string, lines []string) error {
 			Code:    code,
 			Content: strings.Join(lines[1:], "\n"),
 		}
+		e.lastResponse = &e.SuccessResponse
 	case KWErrorResponse:
 		lines = stripKeyword(KWErrorResponse, lines)
-		code, err := strconv.Atoi(lines[0])
+		fields := strings.Fields(lines[0])
+		if len(fields) == 0 || len(fields) > 2 {
+			return fmt.Errorf("%s must be followed by a code and an optional error model, got %q", KWErrorResponse, lines[0])
+		}
+		code, err := strconv.Atoi(fields[0])
 		if err != nil {
 			return err
 		}
@@ -148,13 +287,41 @@ func parseKeyword(e *Endpoint, kw string, lines []string) error {
 			Code:    code,
 			Content: strings.Join(lines[1:], "\n"),
 		}
+		if len(fields) == 2 {
+			er.Model = fields[1]
+		}
 		e.ErrorResponses = append(e.ErrorResponses, er)
+		e.lastResponse = &e.ErrorResponses[len(e.ErrorResponses)-1]
 	case KWExample:
 		lines = stripKeyword(KWExample, lines)
-		e.Examples = append(e.Examples, strings.Join(lines, "\n"))
+		example := strings.Join(lines, "\n")
+		e.Examples = append(e.Examples, example)
+		if r, err := parseCurl(example); err == nil {
+			e.Requests = append(e.Requests, r)
+		}
 	case KWNotes:
 		lines = stripKeyword(KWNotes, lines)
 		e.Notes = strings.Join(lines, "\n")
+	case KWTags:
+		lines = stripKeyword(KWTags, lines)
+		for _, tag := range strings.Split(strings.Join(lines, ","), ",") {
+			if tag = strings.TrimSpace(tag); tag != "" {
+				e.Tags = append(e.Tags, tag)
+			}
+		}
+	case KWAuth:
+		auth, err := parseAuth(strings.TrimPrefix(lines[0], KWAuth))
+		if err != nil {
+			return err
+		}
+		e.Auth = auth
+	case KWVersion:
+		e.Version = strings.TrimSpace(strings.TrimPrefix(lines[0], KWVersion))
+	case KWDeprecated:
+		e.DeprecationNote = strings.TrimSpace(strings.Join(lines[1:], "\n"))
+		if err := parseDeprecated(e, strings.TrimPrefix(lines[0], KWDeprecated)); err != nil {
+			return err
+		}
 	default:
 		return fmt.Errorf("Unknown keyword: %s", kw)
 	}
@@ -162,42 +329,332 @@ func parseKeyword(e *Endpoint, kw string, lines []string) error {
 	return nil
 }
 
+// parseParameter parses the lines of a Parameter, Body Parameter or Response
+// Field section, after the keyword has been stripped, with rx matching the
+// name, required flag and type.  A type that follows the type grammar is
+// stored in its canonical spelling, and any other type as written, to be
+// reported by Endpoint.Validate, as are invalid constraints.
+func parseParameter(rx *regexp.Regexp, lines []string) (Parameter, bool) {
+	line := strings.TrimSpace(lines[0])
+	locs := constraintRx.FindAllStringSubmatchIndex(line, -1)
+	decl := line
+	if len(locs) > 0 {
+		decl = line[:locs[0][0]]
+	}
+
+	matches := rx.FindStringSubmatch(decl)
+	if len(matches) == 0 {
+		return Parameter{}, false
+	}
+	p := Parameter{
+		Name:        matches[1],
+		Required:    matches[2] == "required",
+		Type:        matches[3],
+		Description: strings.TrimSpace(strings.Join(lines[1:], " ")),
+	}
+	if t, ok := parseParamType(p.Type); ok && p.Type != "" {
+		p.Type = t.String()
+	}
+
+	for i, loc := range locs {
+		end := len(line)
+		if i+1 < len(locs) {
+			end = locs[i+1][0]
+		}
+		value := strings.TrimSpace(line[loc[1]:end])
+		switch line[loc[2]:loc[3]] {
+		case "default":
+			p.Default = value
+		case "enum":
+			p.Enum = strings.Split(value, "|")
+		case "min":
+			p.Min = value
+		case "max":
+			p.Max = value
+		case "pattern":
+			p.Pattern = value
+		case "format":
+			p.Format = value
+		}
+	}
+	return p, true
+}
+
+// parseAuth parses the line of an Auth section, after the keyword has been
+// stripped: the name of a scheme, followed by any number of scopes, e.g.
+// "bearer scope:users.read scope:users.write".  Several scopes can also be
+// given at once, separated by commas.
+func parseAuth(line string) (*Auth, error) {
+	fields := strings.Fields(line)
+	if len(fields) == 0 {
+		return nil, fmt.Errorf("missing auth scheme")
+	}
+
+	a := &Auth{Scheme: fields[0]}
+	for _, field := range fields[1:] {
+		if !strings.HasPrefix(field, "scope:") {
+			return nil, fmt.Errorf("invalid auth option: %s", field)
+		}
+		for _, scope := range strings.Split(strings.TrimPrefix(field, "scope:"), ",") {
+			if scope != "" {
+				a.Scopes = append(a.Scopes, scope)
+			}
+		}
+	}
+	if a.Scheme == AuthNone && len(a.Scopes) > 0 {
+		return nil, fmt.Errorf("auth scopes given for an unauthenticated endpoint")
+	}
+	return a, nil
+}
+
+// parseDeprecated parses the options on the line of a Deprecated section,
+// after the keyword has been stripped, e.g. "since:2026-01-01
+// replacement:get-user-v2 sunset:2026-12-31".  Any of them can be left out.
+func parseDeprecated(e *Endpoint, line string) error {
+	e.Deprecated = true
+	for _, field := range strings.Fields(line) {
+		switch {
+		case strings.HasPrefix(field, "since:"):
+			e.DeprecatedSince = strings.TrimPrefix(field, "since:")
+			if _, err := time.Parse(sunsetLayout, e.DeprecatedSince); err != nil {
+				return fmt.Errorf("invalid deprecation date, expected YYYY-MM-DD: %s", e.DeprecatedSince)
+			}
+		case strings.HasPrefix(field, "replacement:"):
+			e.Replacement = strings.TrimPrefix(field, "replacement:")
+		case strings.HasPrefix(field, "sunset:"):
+			e.Sunset = strings.TrimPrefix(field, "sunset:")
+			if _, err := time.Parse(sunsetLayout, e.Sunset); err != nil {
+				return fmt.Errorf("invalid sunset date, expected YYYY-MM-DD: %s", e.Sunset)
+			}
+		default:
+			return fmt.Errorf("invalid deprecation option: %s", field)
+		}
+	}
+	return nil
+}
+
+// urlVersion returns the version segment of a URL template, e.g. "v1" for
+// "/someapi/v1/hello/:name", or "" if it doesn't have one.
+func urlVersion(urlTemplate string) string {
+	for _, split := range strings.Split(urlTemplate, "/") {
+		if versionSegmentRx.MatchString(split) {
+			return split
+		}
+	}
+	return ""
+}
+
 // parseEndpoint takes an apidoc body (which consists of one or more
 // newline-separated lines) and parses the various keyword sections, populating
 // an Endpoint.  The body for each keyword extends until the next keyword,
-// or until the end of the body.
+// or until the end of the body.  A section that can't be parsed doesn't stop
+// the others from being parsed, and the first such error is returned.
 func parseEndpoint(body string) (*Endpoint, error) {
 	lines := strings.Split(body, "\n")
 
 	e := &Endpoint{}
+	var firstErr error
+	parse := func(kw string, lines []string) {
+		if err := parseKeyword(e, kw, lines); err != nil && firstErr == nil {
+			firstErr = err
+		}
+	}
 	i := -1
 	lastKw := KWNone
 	for j, line := range lines {
 		kw := startsWithKeyword(line)
 		if kw != KWNone {
 			if i >= 0 {
-				if err := parseKeyword(e, lastKw, lines[i:j]); err != nil {
-					return e, err
-				}
+				parse(lastKw, lines[i:j])
 			}
 			i = j
 			lastKw = kw
 		}
 	}
 	if i >= 0 {
-		if err := parseKeyword(e, lastKw, lines[i:]); err != nil {
-			return e, err
+		parse(lastKw, lines[i:])
+	}
+
+	if e.Version == "" {
+		e.Version = urlVersion(e.URLTemplate)
+	}
+	return e, firstErr
+}
+
+// startsWithAPIKeyword returns the keyword that a line of an apidoc-api block
+// starts with, or KWNone.
+func startsWithAPIKeyword(str string) string {
+	switch {
+	case apiDescriptionLineRx.MatchString(str):
+		return KWDescription
+	case versionLineRx.MatchString(str):
+		return KWVersion
+	case apiServerLineRx.MatchString(str):
+		return KWServer
+	case apiContactLineRx.MatchString(str):
+		return KWContact
+	case apiLicenseLineRx.MatchString(str):
+		return KWLicense
+	case str == KWAuth || strings.HasPrefix(str, KWAuth+" "):
+		return KWAuth
+	}
+	return KWNone
+}
+
+// parseAPI takes the body of an apidoc-api block and parses its keyword
+// lines.  Every keyword takes the rest of its line, except for Description,
+// which extends until the next keyword or the end of the body.  Each Auth line
+// declares a named scheme: "Auth name description".
+func parseAPI(title, body string) *API {
+	a := &API{Title: title}
+	var description []string
+	lastKw := KWNone
+	for _, line := range strings.Split(body, "\n") {
+		kw := startsWithAPIKeyword(line)
+		if kw == KWNone {
+			if lastKw == KWDescription {
+				description = append(description, line)
+			}
+			continue
+		}
+
+		lastKw = kw
+		value := strings.TrimSpace(strings.TrimPrefix(line, kw))
+		switch kw {
+		case KWDescription:
+			if value = strings.TrimSpace(strings.TrimPrefix(value, ":")); value != "" {
+				description = append(description, value)
+			}
+		case KWVersion:
+			a.Version = value
+		case KWServer:
+			fields := strings.Fields(value)
+			switch len(fields) {
+			case 0:
+			case 1:
+				a.Servers = append(a.Servers, Server{URL: fields[0]})
+			default:
+				a.Servers = append(a.Servers, Server{
+					Name: strings.Join(fields[:len(fields)-1], " "),
+					URL:  fields[len(fields)-1],
+				})
+			}
+		case KWContact:
+			a.Contact = value
+		case KWLicense:
+			a.License = value
+		case KWAuth:
+			if fields := strings.Fields(value); len(fields) > 0 {
+				a.Auth = append(a.Auth, AuthScheme{
+					Name:        fields[0],
+					Description: strings.Join(fields[1:], " "),
+				})
+			}
+		}
+	}
+	a.Description = strings.TrimSpace(strings.Join(description, "\n"))
+	return a
+}
+
+// startsWithErrorModelKeyword returns the keyword at the start of a line of an
+// apidoc-error block, or KWNone.
+func startsWithErrorModelKeyword(str string) string {
+	for _, kw := range []string{KWDescription, KWContentType, KWExample, KWResponseField} {
+		if strings.HasPrefix(str, kw) {
+			return kw
 		}
 	}
+	return KWNone
+}
 
-	return e, nil
+// dedent joins lines after removing the indentation that they all share, so
+// that an example keeps its own indentation but not that of the comment.
+func dedent(lines []string) string {
+	prefix := ""
+	first := true
+	for _, line := range lines {
+		if strings.TrimSpace(line) == "" {
+			continue
+		}
+		indent := line[:len(line)-len(strings.TrimLeft(line, " \t"))]
+		if first {
+			prefix, first = indent, false
+		}
+		for !strings.HasPrefix(indent, prefix) {
+			prefix = prefix[:len(prefix)-1]
+		}
+	}
+	out := make([]string, len(lines))
+	for i, line := range lines {
+		out[i] = strings.TrimPrefix(line, prefix)
+	}
+	return strings.Join(out, "\n")
+}
+
+// parseErrorModel takes the body of an apidoc-error block.  The text before
+// the first keyword describes the model, as does a Description section.  The
+// Example section holds an example body, and Response Field sections describe
+// its fields, as they do for a Response.
+func parseErrorModel(name, body string) ErrorModel {
+	m := ErrorModel{Name: name, ContentType: "application/json"}
+	var description []string
+	lines := strings.Split(body, "\n")
+	start, kw := 0, KWDescription
+	for j := 0; j <= len(lines); j++ {
+		next := KWNone
+		if j < len(lines) {
+			if next = startsWithErrorModelKeyword(lines[j]); next == KWNone {
+				continue
+			}
+		}
+
+		if section := lines[start:j]; len(section) > 0 {
+			section = stripKeyword(kw, section)
+			switch kw {
+			case KWDescription:
+				description = append(description, section...)
+			case KWContentType:
+				m.ContentType = section[0]
+			case KWExample:
+				m.Content = strings.TrimSpace(dedent(section))
+			case KWResponseField:
+				if f, ok := parseParameter(responseFieldRx, section); ok {
+					m.Fields = append(m.Fields, f)
+				}
+			}
+		}
+		start, kw = j, next
+	}
+	m.Description = strings.TrimSpace(strings.Join(description, "\n"))
+	return m
 }
 
 // A reader read a series of CommentGroups, looking for, and attempting to parse
 // apidoc text blocks.
 type reader struct {
-	strict    bool
 	endpoints []*Endpoint
+	tags      []Tag
+	api       *API
+	errors    []ErrorModel
+
+	// invalid holds the validation errors of the endpoints that were read
+	invalid []error
+
+	// err is the error, if any, that stopped the apidoc blocks from being read
+	err error
+
+	// handlers maps doc comments to the key of the function they document,
+	// as given by funcKey
+	handlers map[*ast.CommentGroup]string
+
+	// registrations maps the keys of handlers to the routes they are
+	// registered for with a mux
+	registrations map[string][]registration
+}
+
+// model returns everything that the reader has read.
+func (r *reader) model() *apiModel {
+	return &apiModel{API: r.api, Tags: r.tags, Errors: r.errors, Endpoints: r.endpoints}
 }
 
 // readDocs extracts apidoc from comments.  An apidoc must start at the
@@ -209,10 +666,11 @@ func (r *reader) readDocs(comments []*ast.CommentGroup) error {
 	for _, group := range comments {
 		i := -1 // comment index of most recent note start, valid if >= 0
 		list := group.List
+		handler := r.handlers[group]
 		for j, c := range list {
 			if apidocCommentRx.MatchString(c.Text) {
 				if i >= 0 {
-					if err := r.readDoc(list[i:j]); err != nil {
+					if err := r.readDoc(list[i:j], handler); err != nil {
 						return err
 					}
 				}
@@ -220,7 +678,7 @@ func (r *reader) readDocs(comments []*ast.CommentGroup) error {
 			}
 		}
 		if i >= 0 {
-			if err := r.readDoc(list[i:]); err != nil {
+			if err := r.readDoc(list[i:], handler); err != nil {
 				return err
 			}
 		}
@@ -228,26 +686,93 @@ func (r *reader) readDocs(comments []*ast.CommentGroup) error {
 	return nil
 }
 
-// readDoc collects a single api doc from a sequence of comments.
-func (r *reader) readDoc(list []*ast.Comment) error {
+// readDoc collects a single api doc from a sequence of comments.  handler is
+// the name of the function that the comments document, if any.
+func (r *reader) readDoc(list []*ast.Comment, handler string) error {
 	text := (&ast.CommentGroup{List: list}).Text()
 	if m := apidocMarkerRx.FindStringSubmatchIndex(text); m != nil {
 		// The doc body starts after the marker.
 		body := text[m[1]:]
+		switch text[m[2]:m[3]] {
+		case "apidoc-tag":
+			r.tags = append(r.tags, Tag{
+				Name:        text[m[4]:m[5]],
+				Description: strings.TrimSpace(body),
+			})
+			return nil
+		case "apidoc-api":
+			if r.api == nil {
+				r.api = parseAPI(text[m[4]:m[5]], body)
+			}
+			return nil
+		case "apidoc-error":
+			em := parseErrorModel(text[m[4]:m[5]], body)
+			res := Response{Content: em.Content, Fields: em.Fields}
+			if err := res.checkFields(); err != nil {
+				r.invalid = append(r.invalid, fmt.Errorf("apidoc-error(%s): %s", em.Name, err))
+			}
+			r.errors = append(r.errors, em)
+			return nil
+		}
 		if body != "" {
+			// an endpoint that can't be parsed is reported and left out, so
+			// that the renderers only ever see fully parsed endpoints
 			e, err := parseEndpoint(body)
+			e.Name = text[m[4]:m[5]]
 			if err != nil {
-				return err
+				r.invalid = append(r.invalid, fmt.Errorf("apidoc(%s): %s", e.Name, err))
+				return nil
+			}
+			if err := r.applyRegistration(e, handler); err != nil {
+				r.invalid = append(r.invalid, fmt.Errorf("apidoc(%s): %s", e.Name, err))
 			}
 			if err := e.Validate(); err != nil {
-				if r.strict {
-					log.Fatalf("validation error: %s\n", err.Error())
-				} else {
-					log.Printf("validation error: %s\n", err.Error())
-				}
+				r.invalid = append(r.invalid, fmt.Errorf("apidoc(%s): %s", e.Name, err))
 			}
 			r.endpoints = append(r.endpoints, e)
 		}
 	}
 	return nil
 }
+
+// applyRegistration fills in the routes of an Endpoint from the mux
+// registrations of its handler, when they aren't documented.  An error is
+// returned when they are documented, but differ from the registrations.
+func (r *reader) applyRegistration(e *Endpoint, handler string) error {
+	regs := r.registrations[handler]
+	if handler == "" || len(regs) == 0 {
+		return nil
+	}
+
+	if len(e.Routes) == 0 && e.Method == "" && e.URLTemplate == "" {
+		for _, reg := range regs {
+			e.Routes = append(e.Routes, Route{Method: reg.Method, URLTemplate: reg.Path})
+		}
+		e.Method, e.URLTemplate = e.Routes[0].Method, e.Routes[0].URLTemplate
+		if e.Version == "" {
+			e.Version = urlVersion(e.URLTemplate)
+		}
+		return nil
+	}
+
+	documented := e.AllRoutes()
+	var names []string
+	for _, route := range documented {
+		names = append(names, route.String())
+	}
+	for _, reg := range regs {
+		if !reg.matchesAny(documented) {
+			return RouteConflictError{Documented: strings.Join(names, ", "), Registered: reg.String()}
+		}
+	}
+	for _, route := range documented {
+		found := false
+		for _, reg := range regs {
+			found = found || reg.matchesAny([]Route{route})
+		}
+		if !found {
+			return RouteConflictError{Documented: route.String()}
+		}
+	}
+	return nil
+}
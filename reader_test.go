@@ -0,0 +1,343 @@
+// The MIT License (MIT)
+//
+// Copyright (c) 2015 Dylan Carney
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package main
+
+import (
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestStartsWithAPIKeyword(t *testing.T) {
+	tests := map[string]string{
+		"Description":                               KWDescription,
+		"Description: The Widgets API":              KWDescription,
+		"Descriptions of each endpoint follow.":     KWNone,
+		"Version v2":                                KWVersion,
+		"Version 2.1.0":                             KWVersion,
+		"Version 2 of this API drops XML.":          KWNone,
+		"Server https://api.example.com":            KWServer,
+		"Server Production https://api.example.com": KWServer,
+		"Server /api":                               KWServer,
+		"Servers in the EU are slower.":             KWNone,
+		"Server outages are announced by email.":    KWNone,
+		"Contact api@example.com":                   KWContact,
+		"Contact API Team <api@example.com>":        KWContact,
+		"Contact https://example.com/support":       KWContact,
+		"Contact your account manager for access.":  KWNone,
+		"Contact support at api@example.com.":       KWNone,
+		"License MIT":                               KWLicense,
+		"License Apache-2.0 https://www.apache.org": KWLicense,
+		"License terms are in the contract.":        KWNone,
+		"Licensed under MIT":                        KWNone,
+		"Auth bearer OAuth 2 access token":          KWAuth,
+	}
+	for line, want := range tests {
+		if got := startsWithAPIKeyword(line); got != want {
+			t.Errorf("startsWithAPIKeyword(%q) = %q, want %q", line, got, want)
+		}
+	}
+}
+
+func TestParseAPI(t *testing.T) {
+	body := `
+Description
+  The Widgets API.
+Version 2 of this API drops XML.
+Servers in the EU are slower.
+Contact your account manager for access.
+
+Version v2
+Server Production https://api.example.com
+Contact API Team <api@example.com>
+License MIT
+`
+	want := &API{
+		Title:   "Widgets",
+		Version: "v2",
+		Servers: []Server{{Name: "Production", URL: "https://api.example.com"}},
+		Contact: "API Team <api@example.com>",
+		License: "MIT",
+		Description: "The Widgets API.\n" +
+			"Version 2 of this API drops XML.\n" +
+			"Servers in the EU are slower.\n" +
+			"Contact your account manager for access.",
+	}
+	if got := parseAPI("Widgets", body); !reflect.DeepEqual(got, want) {
+		t.Errorf("parseAPI() = %+v, want %+v", got, want)
+	}
+}
+
+func TestParseEndpointAuthProse(t *testing.T) {
+	body := `
+GET /a
+
+Description
+  Lists things.
+Auth tokens are checked by the gateway.
+
+Auth bearer scope:things.read
+`
+	e, err := parseEndpoint(body)
+	if err != nil {
+		t.Fatalf("parseEndpoint() error = %v", err)
+	}
+	if want := "Lists things.\nAuth tokens are checked by the gateway."; !strings.Contains(e.Description, want) {
+		t.Errorf("Description = %q, want it to contain %q", e.Description, want)
+	}
+	if want := (&Auth{Scheme: "bearer", Scopes: []string{"things.read"}}); !reflect.DeepEqual(e.Auth, want) {
+		t.Errorf("Auth = %+v, want %+v", e.Auth, want)
+	}
+}
+
+func TestReadDocSkipsEndpointsThatFailToParse(t *testing.T) {
+	src := `package h
+
+// apidoc(a): Scopes aren't allowed without auth.
+//
+// GET /a
+//
+// Auth none scope:x
+//
+// Success Response 200
+func A() {}
+
+// apidoc(b): Read after the broken block.
+//
+// GET /b
+//
+// Success Response 200
+func B() {}
+`
+	r, err := parseFile(filepath.Join(t.TempDir(), "h.go"), []byte(src))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if r.err != nil {
+		t.Errorf("r.err = %v, want nil", r.err)
+	}
+	if len(r.invalid) != 1 || !strings.HasPrefix(r.invalid[0].Error(), "apidoc(a): ") {
+		t.Errorf("r.invalid = %v, want one error for apidoc(a)", r.invalid)
+	}
+	if len(r.endpoints) != 1 || r.endpoints[0].Name != "b" {
+		t.Errorf("r.endpoints = %v, want only apidoc(b)", r.endpoints)
+	}
+}
+
+func TestTagsKeyword(t *testing.T) {
+	tests := []struct {
+		line string
+		want string
+	}{
+		{"Tags", KWTags},
+		{"Tags users", KWTags},
+		{"Tags users, admin", KWTags},
+		{"Tags users,admin,", KWTags},
+		{"Tags are normalised to lower case.", KWNone},
+		{"Tags users and admins", KWNone},
+		{"Tagsfoo", KWNone},
+	}
+	for _, tt := range tests {
+		if got := startsWithKeyword(tt.line); got != tt.want {
+			t.Errorf("startsWithKeyword(%q) = %q, want %q", tt.line, got, tt.want)
+		}
+	}
+}
+
+func TestParseEndpointTags(t *testing.T) {
+	tests := []struct {
+		name        string
+		body        string
+		tags        []string
+		description string
+	}{
+		{
+			name: "on the keyword line",
+			body: "GET /a\n\nTags users, admin\n",
+			tags: []string{"users", "admin"},
+		},
+		{
+			name: "with spaces on the following lines",
+			body: "GET /a\n\nTags\n  User management, admin,\n  Billing\n",
+			tags: []string{"User management", "admin", "Billing"},
+		},
+		{
+			name:        "prose",
+			body:        "GET /a\n\nDescription\nTags are normalised, and sorted.\n",
+			description: "Tags are normalised, and sorted.\n",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			e, err := parseEndpoint(tt.body)
+			if err != nil {
+				t.Fatalf("parseEndpoint() error = %v", err)
+			}
+			if !reflect.DeepEqual(e.Tags, tt.tags) || e.Description != tt.description {
+				t.Errorf("parseEndpoint() tags = %q, description = %q, want %q, %q", e.Tags, e.Description, tt.tags, tt.description)
+			}
+		})
+	}
+}
+
+func TestDeprecatedKeyword(t *testing.T) {
+	tests := []struct {
+		line string
+		want string
+	}{
+		{"Deprecated", KWDeprecated},
+		{"Deprecated replacement:getUserV2", KWDeprecated},
+		{"Deprecated since:2026-01-01 sunset:2026-12-31", KWDeprecated},
+		{"Deprecated since the move to v2.", KWNone},
+		{"Deprecated endpoints are listed last.", KWNone},
+		{"Deprecation is announced by email.", KWNone},
+	}
+	for _, tt := range tests {
+		if got := startsWithKeyword(tt.line); got != tt.want {
+			t.Errorf("startsWithKeyword(%q) = %q, want %q", tt.line, got, tt.want)
+		}
+	}
+}
+
+func TestParseEndpointDeprecated(t *testing.T) {
+	tests := []struct {
+		name    string
+		body    string
+		want    Endpoint
+		headers []Header
+		wantErr bool
+	}{
+		{
+			name:    "bare keyword with a note",
+			body:    "GET /a\n\nDeprecated\n  Use getUserV2 instead.\n",
+			want:    Endpoint{Deprecated: true, DeprecationNote: "Use getUserV2 instead."},
+			headers: []Header{{Name: "Deprecation", Value: "true"}},
+		},
+		{
+			name: "options",
+			body: "GET /a\n\nDeprecated since:2026-01-01 replacement:getUserV2 sunset:2026-12-31\n",
+			want: Endpoint{Deprecated: true, DeprecatedSince: "2026-01-01", Replacement: "getUserV2", Sunset: "2026-12-31"},
+			headers: []Header{
+				{Name: "Deprecation", Value: "@1767225600"},
+				{Name: "Sunset", Value: "Thu, 31 Dec 2026 00:00:00 GMT"},
+			},
+		},
+		{
+			name: "prose",
+			body: "GET /a\n\nDescription\nDeprecated since the move to v2.\n",
+			want: Endpoint{Description: "Deprecated since the move to v2.\n"},
+		},
+		{
+			name:    "invalid option",
+			body:    "GET /a\n\nDeprecated sunset:soon\n",
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			e, err := parseEndpoint(tt.body)
+			if tt.wantErr {
+				if err == nil {
+					t.Error("parseEndpoint() error = nil, want an error")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("parseEndpoint() error = %v", err)
+			}
+			got := Endpoint{
+				Description:     e.Description,
+				Deprecated:      e.Deprecated,
+				DeprecatedSince: e.DeprecatedSince,
+				DeprecationNote: e.DeprecationNote,
+				Replacement:     e.Replacement,
+				Sunset:          e.Sunset,
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("parseEndpoint() = %+v, want %+v", got, tt.want)
+			}
+			if headers := e.DeprecationHeaders(); !reflect.DeepEqual(headers, tt.headers) {
+				t.Errorf("DeprecationHeaders() = %v, want %v", headers, tt.headers)
+			}
+		})
+	}
+}
+
+func TestVersionKeyword(t *testing.T) {
+	tests := []struct {
+		line string
+		want string
+	}{
+		{"Version v2", KWVersion},
+		{"Version 2", KWVersion},
+		{"Version v2beta1", KWVersion},
+		{"Version 2.1.0", KWVersion},
+		{"Version 2 adds paging.", KWNone},
+		{"Version v2 of this endpoint is faster.", KWNone},
+		{"Versioning follows the URL.", KWNone},
+		{"Version", KWNone},
+	}
+	for _, tt := range tests {
+		if got := startsWithKeyword(tt.line); got != tt.want {
+			t.Errorf("startsWithKeyword(%q) = %q, want %q", tt.line, got, tt.want)
+		}
+	}
+}
+
+func TestParseEndpointVersion(t *testing.T) {
+	tests := []struct {
+		name        string
+		body        string
+		version     string
+		description string
+	}{
+		{
+			name:    "from the URL",
+			body:    "GET /api/v1/users\n",
+			version: "v1",
+		},
+		{
+			name:    "keyword",
+			body:    "GET /api/users\n\nVersion v2\n",
+			version: "v2",
+		},
+		{
+			name:        "prose",
+			body:        "GET /api/v1/users\n\nDescription\nVersion 2 adds paging.\n",
+			version:     "v1",
+			description: "Version 2 adds paging.\n",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			e, err := parseEndpoint(tt.body)
+			if err != nil {
+				t.Fatalf("parseEndpoint() error = %v", err)
+			}
+			if e.Version != tt.version || e.Description != tt.description {
+				t.Errorf("parseEndpoint() version = %q, description = %q, want %q, %q", e.Version, e.Description, tt.version, tt.description)
+			}
+		})
+	}
+}
@@ -0,0 +1,334 @@
+// The MIT License (MIT)
+//
+// Copyright (c) 2015 Dylan Carney
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package main
+
+import (
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"log"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+// A registration is a route that a handler is registered for with a mux, e.g.
+// mux.HandleFunc("GET /items/{id}", getItem).  Method is empty when the
+// pattern doesn't give one.
+type registration struct {
+	Method string
+	Path   string
+}
+
+func (reg registration) String() string {
+	return strings.TrimSpace(reg.Method + " " + reg.Path)
+}
+
+// matchesAny reports whether the registration is for one of routes.  A
+// registration without a method matches a route with any method.
+func (reg registration) matchesAny(routes []Route) bool {
+	for _, route := range routes {
+		if (reg.Method == "" || reg.Method == route.Method) && canonicalPath(reg.Path) == canonicalPath(route.URLTemplate) {
+			return true
+		}
+	}
+	return false
+}
+
+// packageRegistrations finds the routes that handlers are registered for in
+// the package of f, which was parsed from inputPath.  The other files of the
+// package are read from the same directory, or from the -rev revision.
+// Handlers are keyed like handlerDocs keys them: functions by their name, and
+// methods by their receiver type and name, e.g. "users.list".
+//
+// A method value whose receiver type can't be worked out from the source,
+// such as s.users.list, is taken to be the only method of that name in the
+// package.  When there are several, its registrations are left out, and
+// logged, rather than given to all of them.
+func packageRegistrations(inputPath string, f *ast.File) map[string][]registration {
+	regs := map[string][]registration{}
+	funcs := map[string][]string{} // the handler keys of each function and method name
+	findRegistrations(f, regs)
+	addFuncs(f, funcs)
+
+	dir := filepath.Dir(inputPath)
+	var names []string
+	if opts.rev != "" {
+		names, _ = listRevision(opts.rev, dir)
+	} else if entries, err := os.ReadDir(dir); err == nil {
+		for _, entry := range entries {
+			names = append(names, entry.Name())
+		}
+	}
+
+	fset := token.NewFileSet()
+	for _, name := range names {
+		path := filepath.Join(dir, name)
+		if !strings.HasSuffix(name, ".go") || strings.HasSuffix(name, "_test.go") || path == filepath.Clean(inputPath) {
+			continue
+		}
+		var src interface{}
+		if opts.rev != "" {
+			b, err := readRevision(opts.rev, path)
+			if err != nil {
+				continue
+			}
+			src = b
+		}
+		other, err := parser.ParseFile(fset, path, src, 0)
+		if err != nil || other.Name.Name != f.Name.Name {
+			continue
+		}
+		findRegistrations(other, regs)
+		addFuncs(other, funcs)
+	}
+
+	for key, rs := range regs {
+		name := strings.TrimPrefix(key, "?.")
+		if name == key {
+			continue
+		}
+		delete(regs, key)
+		switch keys := funcs[name]; len(keys) {
+		case 0:
+		case 1:
+			regs[keys[0]] = append(regs[keys[0]], rs...)
+		default:
+			log.Printf("can't tell which of %s handles %s, not inferring its routes\n", strings.Join(keys, ", "), rs[0])
+		}
+	}
+	return regs
+}
+
+// addFuncs adds the handler key of each function and method declared in f to
+// funcs, under its name.
+func addFuncs(f *ast.File, funcs map[string][]string) {
+	for _, decl := range f.Decls {
+		if fd, ok := decl.(*ast.FuncDecl); ok {
+			funcs[fd.Name.Name] = append(funcs[fd.Name.Name], funcKey(fd))
+		}
+	}
+}
+
+// findRegistrations adds the handler registrations in a file to regs.  Both
+// Go 1.22 ServeMux patterns, mux.HandleFunc("GET /items/{id}", h), and
+// gorilla/mux routes, r.HandleFunc("/items/{id}", h).Methods("GET"), are
+// recognized.
+func findRegistrations(f *ast.File, regs map[string][]registration) {
+	imports := map[string]bool{}
+	for _, spec := range f.Imports {
+		path, _ := strconv.Unquote(spec.Path.Value)
+		name := path[strings.LastIndex(path, "/")+1:]
+		if spec.Name != nil {
+			name = spec.Name.Name
+		}
+		imports[name] = true
+	}
+	handlerName := func(expr ast.Expr) string {
+		return handlerKey(expr, imports)
+	}
+	seen := map[*ast.CallExpr]bool{}
+	ast.Inspect(f, func(n ast.Node) bool {
+		call, ok := n.(*ast.CallExpr)
+		if !ok || seen[call] {
+			return true
+		}
+		sel, ok := call.Fun.(*ast.SelectorExpr)
+		if !ok {
+			return true
+		}
+
+		switch sel.Sel.Name {
+		case "Handle", "HandleFunc":
+			if handler, reg, ok := parseRegistration(call, handlerName); ok {
+				regs[handler] = append(regs[handler], reg)
+			}
+		case "Methods":
+			inner, ok := sel.X.(*ast.CallExpr)
+			if !ok {
+				return true
+			}
+			handler, reg, ok := parseRegistration(inner, handlerName)
+			if !ok || reg.Method != "" {
+				return true
+			}
+			seen[inner] = true
+			for _, arg := range call.Args {
+				if method, ok := stringLit(arg); ok {
+					regs[handler] = append(regs[handler], registration{Method: strings.ToUpper(method), Path: reg.Path})
+				}
+			}
+		}
+		return true
+	})
+}
+
+// parseRegistration parses a call like mux.HandleFunc(pattern, handler),
+// returning the key of the handler, as given by handlerName, and the route of
+// the pattern.
+func parseRegistration(call *ast.CallExpr, handlerName func(ast.Expr) string) (string, registration, bool) {
+	sel, ok := call.Fun.(*ast.SelectorExpr)
+	if !ok || (sel.Sel.Name != "Handle" && sel.Sel.Name != "HandleFunc") || len(call.Args) != 2 {
+		return "", registration{}, false
+	}
+	pattern, ok := stringLit(call.Args[0])
+	handler := handlerName(call.Args[1])
+	if !ok || handler == "" {
+		return "", registration{}, false
+	}
+
+	var reg registration
+	if i := strings.IndexAny(pattern, " \t"); i >= 0 {
+		reg.Method, pattern = pattern[:i], strings.TrimSpace(pattern[i:])
+	}
+	// a ServeMux pattern can start with a host, e.g. "example.com/items"
+	i := strings.Index(pattern, "/")
+	if i < 0 {
+		return "", registration{}, false
+	}
+	reg.Path = pattern[i:]
+	return handler, reg, true
+}
+
+// handlerKey returns the key of the function or method that handles the
+// requests of a registration: the name of a function, or the receiver type
+// and name of a method, e.g. "users.list" for u.list where u is a users.  A
+// method whose receiver type isn't known is keyed as "?.list".  Functions of
+// imported packages can't be documented in this one, and are left out.
+// Handlers that are wrapped, as in http.HandlerFunc(h) or auth(h), are keyed
+// by their last argument.
+func handlerKey(expr ast.Expr, imports map[string]bool) string {
+	switch x := expr.(type) {
+	case *ast.Ident:
+		return x.Name
+	case *ast.SelectorExpr:
+		if id, ok := x.X.(*ast.Ident); ok && id.Obj == nil && imports[id.Name] {
+			return ""
+		}
+		if recv := exprTypeName(x.X); recv != "" {
+			return recv + "." + x.Sel.Name
+		}
+		return "?." + x.Sel.Name
+	case *ast.CallExpr:
+		if len(x.Args) > 0 {
+			return handlerKey(x.Args[len(x.Args)-1], imports)
+		}
+	}
+	return ""
+}
+
+// exprTypeName returns the name of the type of expr, as far as it can be told
+// from the source of the file alone: expr must be a type, as in the method
+// expression (*users).list, a composite literal, or a variable that is
+// declared with a type or assigned one of those.  It returns "" otherwise.
+func exprTypeName(expr ast.Expr) string {
+	switch x := expr.(type) {
+	case *ast.ParenExpr:
+		return exprTypeName(x.X)
+	case *ast.StarExpr:
+		return exprTypeName(x.X)
+	case *ast.UnaryExpr:
+		if x.Op == token.AND {
+			return exprTypeName(x.X)
+		}
+	case *ast.CompositeLit:
+		return typeName(x.Type)
+	case *ast.CallExpr:
+		if id, ok := x.Fun.(*ast.Ident); ok && id.Name == "new" && len(x.Args) == 1 {
+			return typeName(x.Args[0])
+		}
+	case *ast.Ident:
+		if x.Obj == nil {
+			return ""
+		}
+		switch decl := x.Obj.Decl.(type) {
+		case *ast.TypeSpec:
+			return decl.Name.Name
+		case *ast.Field:
+			return typeName(decl.Type)
+		case *ast.ValueSpec:
+			if decl.Type != nil {
+				return typeName(decl.Type)
+			}
+			for i, name := range decl.Names {
+				if name.Obj == x.Obj && i < len(decl.Values) {
+					return exprTypeName(decl.Values[i])
+				}
+			}
+		case *ast.AssignStmt:
+			for i, lhs := range decl.Lhs {
+				if id, ok := lhs.(*ast.Ident); ok && id.Obj == x.Obj && len(decl.Lhs) == len(decl.Rhs) {
+					return exprTypeName(decl.Rhs[i])
+				}
+			}
+		}
+	}
+	return ""
+}
+
+// typeName returns the name of a type declared in the package, e.g. "users"
+// for *users or users[T], or "" for any other type.
+func typeName(expr ast.Expr) string {
+	switch x := expr.(type) {
+	case *ast.Ident:
+		return x.Name
+	case *ast.StarExpr:
+		return typeName(x.X)
+	case *ast.IndexExpr:
+		return typeName(x.X)
+	case *ast.IndexListExpr:
+		return typeName(x.X)
+	}
+	return ""
+}
+
+func stringLit(expr ast.Expr) (string, bool) {
+	lit, ok := expr.(*ast.BasicLit)
+	if !ok || lit.Kind != token.STRING {
+		return "", false
+	}
+	s, err := strconv.Unquote(lit.Value)
+	return s, err == nil
+}
+
+// handlerDocs maps the doc comment of each function and method in f to its
+// key, so that the apidoc blocks in it can be matched with registrations.
+func handlerDocs(f *ast.File) map[*ast.CommentGroup]string {
+	docs := map[*ast.CommentGroup]string{}
+	for _, decl := range f.Decls {
+		if fd, ok := decl.(*ast.FuncDecl); ok && fd.Doc != nil {
+			docs[fd.Doc] = funcKey(fd)
+		}
+	}
+	return docs
+}
+
+// funcKey returns the handler key of a function or method declaration: its
+// name, prefixed with its receiver type for a method, e.g. "users.list".
+func funcKey(fd *ast.FuncDecl) string {
+	if fd.Recv == nil || len(fd.Recv.List) == 0 {
+		return fd.Name.Name
+	}
+	return typeName(fd.Recv.List[0].Type) + "." + fd.Name.Name
+}
@@ -0,0 +1,246 @@
+// The MIT License (MIT)
+//
+// Copyright (c) 2015 Dylan Carney
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+// A CodeSample is a snippet that shows how to call an Endpoint from a
+// particular language or tool.
+type CodeSample struct {
+
+	// Lang is the syntax-highlighting identifier of the snippet, e.g. "python"
+	Lang string
+
+	// Label is the human-readable name of the language or tool
+	Label string
+
+	// Code is the snippet itself
+	Code string
+}
+
+// sampleGenerators produce the CodeSamples for an Endpoint, in the order they
+// are rendered.
+var sampleGenerators = []struct {
+	lang, label string
+	gen         func(method, url string, headers []Header, body string) string
+}{
+	{"shell", "curl", curlSample},
+	{"go", "Go", goSample},
+	{"python", "Python", pythonSample},
+	{"javascript", "JavaScript", fetchSample},
+	{"shell", "HTTPie", httpieSample},
+}
+
+// CodeSamples generates request snippets for the Endpoint from its method, URL
+// template, headers and body, in curl, Go, Python, JavaScript and HTTPie.
+// Values for the URL params and the host are taken from the first example
+// request, if there is one.
+func (e *Endpoint) CodeSamples() []CodeSample {
+	if e.Method == "" || e.URLTemplate == "" {
+		return nil
+	}
+
+	url := sampleURL(e)
+	headers := requestHeaders(e)
+	body := requestBody(e)
+
+	samples := make([]CodeSample, len(sampleGenerators))
+	for i, g := range sampleGenerators {
+		samples[i] = CodeSample{
+			Lang:  g.lang,
+			Label: g.label,
+			Code:  g.gen(e.Method, url, headers, body),
+		}
+	}
+	return samples
+}
+
+// sampleURL returns the full URL of an Endpoint, with the URL params filled in.
+func sampleURL(e *Endpoint) string {
+	host := "https://host"
+	if r := exampleRequest(e); r != nil {
+		if u, err := r.parseURL(); err == nil && u.Host != "" {
+			host = u.Scheme + "://" + u.Host
+		}
+	}
+
+	splits := strings.Split(e.URLTemplate, "/")
+	for i, seg := range pathSegments(e.URLTemplate) {
+		switch {
+		case seg.End:
+			splits[i] = ""
+		case seg.Param != "":
+			splits[i] = paramValue(e, Parameter{Name: seg.Param})
+		}
+	}
+
+	var query []string
+	for _, p := range e.QueryParams() {
+		query = append(query, p.Name+"="+paramValue(e, p))
+	}
+
+	url := host + strings.Join(splits, "/")
+	if len(query) > 0 {
+		url += "?" + strings.Join(query, "&")
+	}
+	return url
+}
+
+// compactJSON returns body compacted onto a single line when it is valid JSON,
+// and unchanged otherwise.
+func compactJSON(body string) string {
+	var b bytes.Buffer
+	if err := json.Compact(&b, []byte(body)); err != nil {
+		return body
+	}
+	return b.String()
+}
+
+// shellQuote quotes s for a POSIX shell.
+func shellQuote(s string) string {
+	return "'" + strings.Replace(s, "'", `'\''`, -1) + "'"
+}
+
+// goString returns s as a Go string literal, preferring a raw string.
+func goString(s string) string {
+	if strings.ContainsAny(s, "`\r") {
+		return strconv.Quote(s)
+	}
+	return "`" + s + "`"
+}
+
+// pyString returns s as a Python string literal, escaping what Python's own
+// repr would.  Go's %q is not used, since some of its escapes, such as the \x
+// of an invalid byte, mean something else in Python.
+func pyString(s string) string {
+	var b strings.Builder
+	b.WriteByte('"')
+	for _, r := range s {
+		switch {
+		case r == '\\' || r == '"':
+			b.WriteByte('\\')
+			b.WriteRune(r)
+		case r == '\n':
+			b.WriteString(`\n`)
+		case r == '\r':
+			b.WriteString(`\r`)
+		case r == '\t':
+			b.WriteString(`\t`)
+		case r < 0x20 || r == 0x7f:
+			fmt.Fprintf(&b, `\x%02x`, r)
+		case r > 0xffff && !unicode.IsPrint(r):
+			fmt.Fprintf(&b, `\U%08x`, r)
+		case !unicode.IsPrint(r):
+			fmt.Fprintf(&b, `\u%04x`, r)
+		default:
+			b.WriteRune(r)
+		}
+	}
+	b.WriteByte('"')
+	return b.String()
+}
+
+func curlSample(method, url string, headers []Header, body string) string {
+	lines := []string{fmt.Sprintf("curl -X %s %s", method, shellQuote(url))}
+	for _, h := range headers {
+		lines = append(lines, "  -H "+shellQuote(h.Name+": "+h.Value))
+	}
+	if body != "" {
+		lines = append(lines, "  -d "+shellQuote(compactJSON(body)))
+	}
+	return strings.Join(lines, " \\\n")
+}
+
+func goSample(method, url string, headers []Header, body string) string {
+	var b strings.Builder
+	reader := "nil"
+	if body != "" {
+		fmt.Fprintf(&b, "body := strings.NewReader(%s)\n", goString(compactJSON(body)))
+		reader = "body"
+	}
+	fmt.Fprintf(&b, "req, err := http.NewRequest(%q, %q, %s)\n", method, url, reader)
+	b.WriteString("if err != nil {\n\tlog.Fatal(err)\n}\n")
+	for _, h := range headers {
+		fmt.Fprintf(&b, "req.Header.Set(%q, %q)\n", h.Name, h.Value)
+	}
+	b.WriteString("resp, err := http.DefaultClient.Do(req)\n")
+	b.WriteString("if err != nil {\n\tlog.Fatal(err)\n}\n")
+	b.WriteString("defer resp.Body.Close()")
+	return b.String()
+}
+
+func pythonSample(method, url string, headers []Header, body string) string {
+	var b strings.Builder
+	b.WriteString("import requests\n\n")
+	fmt.Fprintf(&b, "response = requests.request(\n    %s,\n    %s,\n", pyString(method), pyString(url))
+	if len(headers) > 0 {
+		b.WriteString("    headers={\n")
+		for _, h := range headers {
+			fmt.Fprintf(&b, "        %s: %s,\n", pyString(h.Name), pyString(h.Value))
+		}
+		b.WriteString("    },\n")
+	}
+	if body != "" {
+		fmt.Fprintf(&b, "    data=%s,\n", pyString(compactJSON(body)))
+	}
+	b.WriteString(")\nprint(response.status_code, response.text)")
+	return b.String()
+}
+
+func fetchSample(method, url string, headers []Header, body string) string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "const response = await fetch(%q, {\n  method: %q,\n", url, method)
+	if len(headers) > 0 {
+		b.WriteString("  headers: {\n")
+		for _, h := range headers {
+			fmt.Fprintf(&b, "    %q: %q,\n", h.Name, h.Value)
+		}
+		b.WriteString("  },\n")
+	}
+	if body != "" {
+		fmt.Fprintf(&b, "  body: %q,\n", compactJSON(body))
+	}
+	b.WriteString("});\nconsole.log(response.status, await response.text());")
+	return b.String()
+}
+
+func httpieSample(method, url string, headers []Header, body string) string {
+	var parts []string
+	if body != "" {
+		// The body is piped in, as a here-string is bash only, and printf
+		// leaves backslashes alone where some echos don't.
+		parts = append(parts, "printf '%s\\n'", shellQuote(compactJSON(body)), "|")
+	}
+	parts = append(parts, "http", method, shellQuote(url))
+	for _, h := range headers {
+		parts = append(parts, shellQuote(h.Name+":"+h.Value))
+	}
+	return strings.Join(parts, " ")
+}
@@ -0,0 +1,78 @@
+// The MIT License (MIT)
+//
+// Copyright (c) 2015 Dylan Carney
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package main
+
+import (
+	"os/exec"
+	"testing"
+)
+
+func TestPyString(t *testing.T) {
+	tests := []struct {
+		s    string
+		want string
+	}{
+		{`{"name":"x"}`, `"{\"name\":\"x\"}"`},
+		{`C:\path`, `"C:\\path"`},
+		{"a\nb\tc", `"a\nb\tc"`},
+		{"bell\a", `"bell\x07"`},
+		{"café", `"café"`},
+		{"zero\u200bwidth", `"zero\u200bwidth"`},
+	}
+	for _, tt := range tests {
+		if got := pyString(tt.s); got != tt.want {
+			t.Errorf("pyString(%q) = %s, want %s", tt.s, got, tt.want)
+		}
+	}
+
+	// Where Python is at hand, check that it reads the literals back as the
+	// strings they were made from.
+	python, err := exec.LookPath("python3")
+	if err != nil {
+		return
+	}
+	for _, tt := range tests {
+		out, err := exec.Command(python, "-c", "import sys; sys.stdout.write("+pyString(tt.s)+")").Output()
+		if err != nil {
+			t.Errorf("python3 could not read %s: %v", pyString(tt.s), err)
+		} else if string(out) != tt.s {
+			t.Errorf("python3 read %s as %q, want %q", pyString(tt.s), out, tt.s)
+		}
+	}
+}
+
+func TestHttpieSample(t *testing.T) {
+	headers := []Header{{Name: "Content-Type", Value: "application/json"}}
+
+	got := httpieSample("POST", "https://host/users", headers, `{"name": "it's"}`)
+	want := `printf '%s\n' '{"name":"it'\''s"}' | http POST 'https://host/users' 'Content-Type:application/json'`
+	if got != want {
+		t.Errorf("httpieSample with a body =\n%s\nwant\n%s", got, want)
+	}
+
+	got = httpieSample("GET", "https://host/users", nil, "")
+	want = `http GET 'https://host/users'`
+	if got != want {
+		t.Errorf("httpieSample without a body =\n%s\nwant\n%s", got, want)
+	}
+}
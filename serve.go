@@ -0,0 +1,190 @@
+// The MIT License (MIT)
+//
+// Copyright (c) 2015 Dylan Carney
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package main
+
+import (
+	"bytes"
+	"flag"
+	"fmt"
+	"log"
+	"net/http"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// A docServer serves the rendered HTML of a set of Go files, re-rendering it
+// whenever one of them changes.
+type docServer struct {
+	paths []string // the files and package directories being watched
+
+	mu      sync.Mutex
+	page    []byte
+	changed chan struct{} // closed, and replaced, on every re-render
+}
+
+// serve implements the serve command, which serves a live preview of the docs
+// for the given files and package directories.
+func serve(args []string) {
+	fs := flag.NewFlagSet("serve", flag.ExitOnError)
+	addr := fs.String("addr", "localhost:6060", "Address to serve the docs on.")
+	interval := fs.Duration("interval", 500*time.Millisecond, "How often to check the input files for changes.")
+	fs.StringVar(&opts.theme, "theme", "light", "Specifies the color theme of the docs [light|dark|auto].")
+	fs.Var(&opts.verbs, "verbs", "Comma-separated list of HTTP methods to recognize besides the standard ones, e.g. PROPFIND,REPORT,QUERY.")
+	fs.Parse(args)
+
+	if fs.NArg() == 0 {
+		log.Fatalf("serve requires at least one Go file or package directory")
+	}
+
+	s := &docServer{
+		paths:   fs.Args(),
+		changed: make(chan struct{}),
+	}
+	go s.watch(*interval)
+
+	http.HandleFunc("/", s.handlePage)
+	http.HandleFunc("/_apidoc/events", s.handleEvents)
+	log.Printf("serving docs on http://%s\n", *addr)
+	log.Fatal(http.ListenAndServe(*addr, nil))
+}
+
+// watch re-renders the docs whenever the size or modification time of any of
+// the input files changes, or a file is added or removed.
+func (s *docServer) watch(interval time.Duration) {
+	last := ""
+	for {
+		files := s.files()
+		var state strings.Builder
+		for _, path := range files {
+			if fi, err := os.Stat(path); err == nil {
+				fmt.Fprintf(&state, "%s %d %d\n", path, fi.Size(), fi.ModTime().UnixNano())
+			}
+		}
+
+		if state.String() != last {
+			last = state.String()
+			s.render(files)
+		}
+		time.Sleep(interval)
+	}
+}
+
+// files returns the Go files named by the paths being watched, expanding
+// package directories into their non-test Go files.
+func (s *docServer) files() []string {
+	var files []string
+	for _, path := range s.paths {
+		fi, err := os.Stat(path)
+		if err != nil || !fi.IsDir() {
+			files = append(files, path)
+			continue
+		}
+		matches, _ := filepath.Glob(filepath.Join(path, "*.go"))
+		for _, match := range matches {
+			if !strings.HasSuffix(match, "_test.go") {
+				files = append(files, match)
+			}
+		}
+	}
+	sort.Strings(files)
+	return files
+}
+
+// render re-parses files, and renders the page that is served.  Parse and
+// validation errors are shown on the page rather than stopping the server.
+func (s *docServer) render(files []string) {
+	var models []*apiModel
+	var errs []string
+	for _, path := range files {
+		r, err := parseFile(path, nil)
+		if err != nil {
+			errs = append(errs, err.Error())
+			continue
+		}
+		if r.err != nil {
+			errs = append(errs, fmt.Sprintf("%s: %s", path, r.err))
+		}
+		for _, err := range r.invalid {
+			errs = append(errs, fmt.Sprintf("%s: %s", path, err))
+		}
+		models = append(models, r.model())
+	}
+	doc := mergeModels(models)
+
+	var b bytes.Buffer
+	page := newHtmlPage(doc)
+	page.Errors = errs
+	page.LiveReload = true
+	err := renderHtmlPage(page, &b)
+	if err != nil {
+		b.Reset()
+		fmt.Fprintf(&b, "could not render docs: %s", err)
+	}
+	log.Printf("rendered %d apidoc(s) with %d error(s)\n", len(doc.Endpoints), len(errs))
+
+	s.mu.Lock()
+	s.page = b.Bytes()
+	close(s.changed)
+	s.changed = make(chan struct{})
+	s.mu.Unlock()
+}
+
+func (s *docServer) handlePage(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	page := s.page
+	s.mu.Unlock()
+
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	w.Write(page)
+}
+
+// handleEvents streams a server-sent event to the page each time the docs are
+// re-rendered, which the page reacts to by reloading itself.
+func (s *docServer) handleEvents(w http.ResponseWriter, r *http.Request) {
+	flusher, ok := w.(http.Flusher)
+	if !ok {
+		http.Error(w, "streaming unsupported", http.StatusInternalServerError)
+		return
+	}
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	flusher.Flush()
+
+	for {
+		s.mu.Lock()
+		changed := s.changed
+		s.mu.Unlock()
+
+		select {
+		case <-changed:
+			fmt.Fprint(w, "data: reload\n\n")
+			flusher.Flush()
+		case <-r.Context().Done():
+			return
+		}
+	}
+}
@@ -0,0 +1,145 @@
+// The MIT License (MIT)
+//
+// Copyright (c) 2015 Dylan Carney
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"log"
+	"net/http"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"text/template"
+)
+
+// sitePage is the data that siteTemplate is rendered with.
+type sitePage struct {
+	Theme      string
+	Stylesheet string
+	Root       string // the relative path from the page to the site root
+	API        *API
+	Groups     []endpointGroup
+	Endpoint   *Endpoint // the endpoint shown on the page, nil for the index
+}
+
+// writeSite writes a static documentation site for doc into dir: an index.html
+// listing every endpoint by group, and a page per endpoint at its permalink.
+func writeSite(dir string, doc *apiModel) error {
+	endpoints := doc.Endpoints
+	names := map[string]bool{}
+	for _, e := range endpoints {
+		if names[anchor(e.Name)] {
+			return fmt.Errorf("duplicate endpoint name: %s", e.Name)
+		}
+		names[anchor(e.Name)] = true
+	}
+
+	fm := template.FuncMap{
+		"statusText": http.StatusText,
+		"anchor":     anchor,
+		"permalink":  permalink,
+		"searchText": searchText,
+		"summary":    summary,
+		"join":       strings.Join,
+		"paragraphs": paragraphs,
+	}
+	t := template.Must(template.New("site").Funcs(fm).Parse(siteTemplate))
+	template.Must(t.New("index").Parse(siteIndexTemplate))
+	template.Must(t.New("endpoint").Parse(htmlEndpointTemplate))
+	template.Must(t.New("params").Parse(htmlParamsTemplate))
+	template.Must(t.New("api").Parse(htmlAPITemplate))
+
+	page := sitePage{
+		Theme:      opts.theme,
+		Stylesheet: stylesheet,
+		API:        doc.API,
+		Groups:     groupVersions(doc),
+	}
+
+	// within a group, the site lists endpoints by URL rather than in source
+	// order, so that related routes end up next to each other
+	for _, g := range page.Groups {
+		sort.SliceStable(g.Endpoints, func(i, j int) bool {
+			a, b := g.Endpoints[i], g.Endpoints[j]
+			if a.URLTemplate != b.URLTemplate {
+				return a.URLTemplate < b.URLTemplate
+			}
+			return a.Method < b.Method
+		})
+	}
+	if err := writePage(t, filepath.Join(dir, "index.html"), page); err != nil {
+		return err
+	}
+
+	page.Root = "../"
+	for _, e := range endpoints {
+		page.Endpoint = e
+		if err := writePage(t, filepath.Join(dir, filepath.FromSlash(permalink(e))), page); err != nil {
+			return err
+		}
+	}
+	if !opts.check {
+		log.Printf("wrote site for %d endpoint(s) to: %s\n", len(endpoints), dir)
+	}
+	return nil
+}
+
+func writePage(t *template.Template, path string, page sitePage) error {
+	var b bytes.Buffer
+	if err := t.ExecuteTemplate(&b, "site", page); err != nil {
+		return err
+	}
+	if !opts.check {
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			return err
+		}
+	}
+	return emit(path, b.Bytes())
+}
+
+// permalink returns the path of the page for an Endpoint, relative to the
+// site root.  It only depends on the apidoc(name), so it stays stable when the
+// endpoint's URL changes.
+func permalink(e *Endpoint) string {
+	return "endpoints/" + anchor(e.Name) + ".html"
+}
+
+// searchText returns the lowercase text that an Endpoint is searched by.
+func searchText(e *Endpoint) string {
+	text := strings.Join([]string{e.Name, e.Method, e.URLTemplate, e.Description}, " ")
+	for _, route := range e.OtherRoutes() {
+		text += " " + route.URLTemplate
+	}
+	return strings.ToLower(strings.Join(strings.Fields(text), " "))
+}
+
+// summary returns the first line of the description of an Endpoint.
+func summary(e *Endpoint) string {
+	description := strings.TrimSpace(e.Description)
+	if i := strings.Index(description, "\n\n"); i >= 0 {
+		description = description[:i]
+	}
+	return strings.Replace(description, "\n", " ", -1)
+}
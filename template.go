@@ -23,27 +23,508 @@
 package main
 
 import (
+	"encoding/json"
+	"fmt"
 	"io"
 	"net/http"
+	"sort"
+	"strconv"
+	"strings"
 	"text/template"
 )
 
-// RenderMarkdown writes a Markdown representation of the specified Endpoint to
-// an io.Writer
-func RenderMarkdown(e *Endpoint, out io.Writer) error {
+// RenderMarkdown writes a Markdown representation of the specified Endpoints
+// to an io.Writer.  When any of them are tagged, they are grouped by tag, and
+// when they span several API versions, they are grouped by version after a
+// matrix of which endpoints exist in which version.
+func RenderMarkdown(doc *apiModel, out io.Writer) error {
 	fm := template.FuncMap{
 		"statusText": http.StatusText,
+		"join":       strings.Join,
+		"cell":       cell,
+		"indent":     indent,
+		"quote":      quote,
 	}
 	t := template.Must(template.New("markdown").Funcs(fm).Parse(markdownTemplate))
-	return t.Execute(out, e)
+	template.Must(t.New("tag").Parse(markdownTagTemplate))
+	template.Must(t.New("params").Parse(markdownParamsTemplate))
+	template.Must(t.New("api").Parse(markdownAPITemplate))
+	template.Must(t.New("versions").Parse(markdownVersionsTemplate))
+	if doc.API != nil {
+		if err := t.ExecuteTemplate(out, "api", doc.API); err != nil {
+			return err
+		}
+	}
+	versions := doc.versions()
+	if len(versions) > 1 {
+		if err := t.ExecuteTemplate(out, "versions", newVersionMatrix(doc, versions)); err != nil {
+			return err
+		}
+	}
+	if !doc.tagged() && len(versions) <= 1 {
+		for _, e := range doc.Endpoints {
+			if err := t.ExecuteTemplate(out, "markdown", e); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+
+	for _, g := range groupVersions(doc) {
+		if err := t.ExecuteTemplate(out, "tag", g); err != nil {
+			return err
+		}
+		for _, e := range g.Endpoints {
+			if err := t.ExecuteTemplate(out, "markdown", e); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// htmlPage is the data that htmlTemplate is rendered with.
+type htmlPage struct {
+	Theme      string
+	Stylesheet string
+	API        *API
+	Endpoints  []*Endpoint
+
+	// Groups are the endpoints grouped by version and tag, or nil if they
+	// are neither tagged nor span several versions
+	Groups []endpointGroup
+
+	// Matrix shows which endpoints exist in which version, or is nil if they
+	// don't span several versions
+	Matrix *versionMatrix
+
+	// Errors are shown above the endpoints, e.g. validation errors in serve
+	Errors []string
+
+	// LiveReload makes the page reload when the serve command sees a change
+	LiveReload bool
+}
+
+func newHtmlPage(doc *apiModel) htmlPage {
+	page := htmlPage{
+		Theme:      opts.theme,
+		Stylesheet: stylesheet,
+		API:        doc.API,
+		Endpoints:  doc.Endpoints,
+	}
+	if versions := doc.versions(); len(versions) > 1 {
+		page.Matrix = newVersionMatrix(doc, versions)
+		page.Groups = groupVersions(doc)
+	} else if doc.tagged() {
+		page.Groups = groupEndpoints(doc)
+	}
+	return page
+}
+
+// RenderHtml writes an HTML page for the specified Endpoints to an io.Writer.
+// When any of them are tagged, they are grouped by tag, and when they span
+// several API versions, the page gets a version switcher.
+func RenderHtml(doc *apiModel, out io.Writer) error {
+	return renderHtmlPage(newHtmlPage(doc), out)
 }
 
-// RenderHtml writes a Markdown representation of the specified Endpoint to
-// an io.Writer
-func RenderHtml(e *Endpoint, out io.Writer) error {
+func renderHtmlPage(page htmlPage, out io.Writer) error {
 	fm := template.FuncMap{
 		"statusText": http.StatusText,
+		"anchor":     anchor,
+		"join":       strings.Join,
+		"paragraphs": paragraphs,
 	}
 	t := template.Must(template.New("html").Funcs(fm).Parse(htmlTemplate))
-	return t.Execute(out, e)
+	template.Must(t.New("endpoint").Parse(htmlEndpointTemplate))
+	template.Must(t.New("params").Parse(htmlParamsTemplate))
+	template.Must(t.New("api").Parse(htmlAPITemplate))
+	template.Must(t.New("versions").Parse(htmlVersionsTemplate))
+	return t.ExecuteTemplate(out, "html", page)
+}
+
+// cell escapes text for use in a Markdown table cell.
+func cell(text string) string {
+	return strings.Replace(text, "|", `\|`, -1)
+}
+
+// indent prefixes every line of text but the first with prefix, so that text
+// placed after an indentation in a template, such as the content or
+// description from an ErrorModel, stays within the block it is rendered in.
+// Blank lines are left empty.
+func indent(prefix, text string) string {
+	lines := strings.Split(text, "\n")
+	for i := 1; i < len(lines); i++ {
+		if strings.TrimSpace(lines[i]) != "" {
+			lines[i] = prefix + lines[i]
+		}
+	}
+	return strings.Join(lines, "\n")
+}
+
+// quote turns every line of text into a line of a Markdown blockquote, so that
+// notes of several lines or paragraphs stay within the quote.
+func quote(text string) string {
+	lines := strings.Split(text, "\n")
+	for i, line := range lines {
+		if strings.TrimSpace(line) == "" {
+			lines[i] = ">"
+		} else {
+			lines[i] = "> " + line
+		}
+	}
+	return strings.Join(lines, "\n")
+}
+
+// paragraphs splits text into its blank-line separated paragraphs.
+func paragraphs(text string) []string {
+	var ps []string
+	for _, p := range strings.Split(strings.TrimSpace(text), "\n\n") {
+		if p = strings.TrimSpace(p); p != "" {
+			ps = append(ps, p)
+		}
+	}
+	return ps
+}
+
+// An endpointGroup is a named set of endpoints that are listed together.
+type endpointGroup struct {
+	Name        string
+	Description string
+	Version     string // the API version of the endpoints, when grouped by version
+	Endpoints   []*Endpoint
+}
+
+// tagged reports whether any of the endpoints in doc are tagged.
+func (doc *apiModel) tagged() bool {
+	for _, e := range doc.Endpoints {
+		if len(e.Tags) > 0 {
+			return true
+		}
+	}
+	return false
+}
+
+// groupEndpoints groups the endpoints of doc under their first tag, keeping
+// them in source order.  Tags described by apidoc-tag blocks come first, in
+// the order they are described, followed by any others alphabetically.
+// Untagged endpoints come last, grouped by the static prefix of their URL.
+func groupEndpoints(doc *apiModel) []endpointGroup {
+	var tagged, untagged []endpointGroup
+	index := map[string]int{}
+	prefixIndex := map[string]int{}
+	for _, tag := range doc.Tags {
+		index[tag.Name] = len(tagged)
+		tagged = append(tagged, endpointGroup{Name: tag.Name, Description: tag.Description})
+	}
+	described := len(tagged)
+
+	for _, e := range doc.Endpoints {
+		if len(e.Tags) == 0 {
+			name := groupName(e)
+			i, ok := prefixIndex[name]
+			if !ok {
+				i = len(untagged)
+				prefixIndex[name] = i
+				untagged = append(untagged, endpointGroup{Name: name})
+			}
+			untagged[i].Endpoints = append(untagged[i].Endpoints, e)
+			continue
+		}
+
+		i, ok := index[e.Tags[0]]
+		if !ok {
+			i = len(tagged)
+			index[e.Tags[0]] = i
+			tagged = append(tagged, endpointGroup{Name: e.Tags[0]})
+		}
+		tagged[i].Endpoints = append(tagged[i].Endpoints, e)
+	}
+
+	undescribed := tagged[described:]
+	sort.Slice(undescribed, func(i, j int) bool { return undescribed[i].Name < undescribed[j].Name })
+	sort.Slice(untagged, func(i, j int) bool { return untagged[i].Name < untagged[j].Name })
+
+	var groups []endpointGroup
+	for _, g := range append(tagged, untagged...) {
+		if len(g.Endpoints) > 0 {
+			groups = append(groups, g)
+		}
+	}
+	return groups
+}
+
+// versions returns the API versions of the endpoints in doc, oldest first.
+func (doc *apiModel) versions() []string {
+	var versions []string
+	seen := map[string]bool{}
+	for _, e := range doc.Endpoints {
+		if e.Version != "" && !seen[e.Version] {
+			seen[e.Version] = true
+			versions = append(versions, e.Version)
+		}
+	}
+	sort.Slice(versions, func(i, j int) bool { return versionLess(versions[i], versions[j]) })
+	return versions
+}
+
+// versionLess orders versions by their numbers, so that "v2" comes before
+// "v10", falling back to plain string order.
+func versionLess(a, b string) bool {
+	na, errA := strconv.Atoi(strings.TrimPrefix(a, "v"))
+	nb, errB := strconv.Atoi(strings.TrimPrefix(b, "v"))
+	if errA == nil && errB == nil && na != nb {
+		return na < nb
+	}
+	return a < b
+}
+
+// groupVersions groups the endpoints of doc by version, and then by tag as
+// groupEndpoints does.  Endpoints without a version come last.  If there is
+// only one version, the endpoints are only grouped by tag.
+func groupVersions(doc *apiModel) []endpointGroup {
+	versions := doc.versions()
+	if len(versions) <= 1 {
+		return groupEndpoints(doc)
+	}
+
+	var groups []endpointGroup
+	for _, v := range append(versions, "") {
+		sub := &apiModel{Tags: doc.Tags}
+		for _, e := range doc.Endpoints {
+			if e.Version == v {
+				sub.Endpoints = append(sub.Endpoints, e)
+			}
+		}
+		for _, g := range groupEndpoints(sub) {
+			g.Version = v
+			groups = append(groups, g)
+		}
+	}
+	return groups
+}
+
+// A versionMatrix shows which endpoints exist in which API version.  Its rows
+// are the method and URL of the endpoints, with the version segment of the URL
+// replaced by "…", which can't be mistaken for a URL param.
+type versionMatrix struct {
+	Versions []string
+	Rows     []versionRow
+}
+
+type versionRow struct {
+	Route string
+
+	// Endpoints holds the endpoint for each of the Versions, or nil where the
+	// route doesn't exist in that version
+	Endpoints []*Endpoint
+}
+
+func newVersionMatrix(doc *apiModel, versions []string) *versionMatrix {
+	m := &versionMatrix{Versions: versions}
+	column := map[string]int{}
+	for i, v := range versions {
+		column[v] = i
+	}
+
+	index := map[string]int{}
+	for _, e := range doc.Endpoints {
+		if e.Version == "" {
+			continue
+		}
+		splits := strings.Split(e.URLTemplate, "/")
+		for i, split := range splits {
+			if split == e.Version {
+				splits[i] = "…"
+			}
+		}
+		route := e.Method + " " + strings.Join(splits, "/")
+
+		i, ok := index[route]
+		if !ok {
+			i = len(m.Rows)
+			index[route] = i
+			m.Rows = append(m.Rows, versionRow{Route: route, Endpoints: make([]*Endpoint, len(versions))})
+		}
+		m.Rows[i].Endpoints[column[e.Version]] = e
+	}
+	return m
+}
+
+// groupName returns the first two static segments of the URLTemplate of an
+// Endpoint, e.g. "/someapi/v1" for "/someapi/v1/:foo/:bar".
+func groupName(e *Endpoint) string {
+	var prefix []string
+	for _, seg := range pathSegments(e.URLTemplate) {
+		if seg.Literal == "" && seg.Param == "" && !seg.End {
+			continue
+		}
+		if seg.Literal == "" || len(prefix) == 2 {
+			break
+		}
+		prefix = append(prefix, seg.Literal)
+	}
+	return "/" + strings.Join(prefix, "/")
+}
+
+// anchor turns a name into a string that is safe to use as an HTML id.
+func anchor(name string) string {
+	return strings.Map(func(r rune) rune {
+		switch {
+		case r >= 'a' && r <= 'z', r >= 'A' && r <= 'Z', r >= '0' && r <= '9', r == '-', r == '_':
+			return r
+		}
+		return '-'
+	}, name)
+}
+
+// RenderJSON writes the JSON model of the specified Endpoints to an io.Writer
+func RenderJSON(doc *apiModel, out io.Writer) error {
+	b, err := json.MarshalIndent(doc, "", "  ")
+	if err != nil {
+		return err
+	}
+	_, err = fmt.Fprintf(out, "%s\n", b)
+	return err
+}
+
+// RenderHTTPFile writes an executable .http request for each of the specified
+// Endpoints to an io.Writer
+func RenderHTTPFile(doc *apiModel, out io.Writer) error {
+	fm := template.FuncMap{
+		"comment":        comment,
+		"varName":        varName,
+		"paramValue":     paramValue,
+		"requestURL":     requestURL,
+		"requestHeaders": requestHeaders,
+		"requestBody":    requestBody,
+	}
+	t := template.Must(template.New("http").Funcs(fm).Parse(httpFileTemplate))
+	for _, e := range doc.Endpoints {
+		if err := t.Execute(out, e); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// comment prefixes each line of s with a "#", so that it is ignored by .http
+// clients.
+func comment(s string) string {
+	lines := strings.Split(strings.TrimSpace(s), "\n")
+	for i, line := range lines {
+		lines[i] = strings.TrimRight("# "+line, " ")
+	}
+	return strings.Join(lines, "\n")
+}
+
+// varName returns the name of the .http file variable that holds the value of
+// a parameter.  Variables are file-scoped, so they are prefixed with the
+// endpoint name to keep them from colliding.
+func varName(e *Endpoint, p Parameter) string {
+	if e.Name == "" {
+		return p.Name
+	}
+	return e.Name + "_" + p.Name
+}
+
+// requestURL returns the URLTemplate of an Endpoint, with the host and params
+// replaced by .http variable references.
+func requestURL(e *Endpoint) string {
+	splits := strings.Split(e.URLTemplate, "/")
+	for i, seg := range pathSegments(e.URLTemplate) {
+		switch {
+		case seg.End:
+			splits[i] = ""
+		case seg.Param != "":
+			splits[i] = "{{" + varName(e, Parameter{Name: seg.Param}) + "}}"
+		}
+	}
+
+	var query []string
+	for _, p := range e.QueryParams() {
+		query = append(query, fmt.Sprintf("%s={{%s}}", p.Name, varName(e, p)))
+	}
+
+	url := "{{host}}" + strings.Join(splits, "/")
+	if len(query) > 0 {
+		url += "?" + strings.Join(query, "&")
+	}
+	return url
+}
+
+// exampleRequest returns the first example request of an Endpoint, or nil if
+// it has none.
+func exampleRequest(e *Endpoint) *Request {
+	if len(e.Requests) == 0 {
+		return nil
+	}
+	return e.Requests[0]
+}
+
+// paramValue returns an initial value for a parameter, taken from the example
+// request if there is one, or else from the parameter's default or its first
+// allowed value.
+func paramValue(e *Endpoint, p Parameter) string {
+	if r := exampleRequest(e); r != nil {
+		if values, ok := e.matchPath(r.Path()); ok && values[p.Name] != "" {
+			return values[p.Name]
+		}
+		if v := r.Query().Get(p.Name); v != "" {
+			return v
+		}
+	}
+	if p.Default != "" {
+		return p.Default
+	}
+	if len(p.Enum) > 0 {
+		return p.Enum[0]
+	}
+	return p.Name
+}
+
+// requestHeaders returns the headers of the example request, adding a JSON
+// Content-Type when the request has a body that doesn't declare its own.
+func requestHeaders(e *Endpoint) []Header {
+	var headers []Header
+	if r := exampleRequest(e); r != nil {
+		headers = append(headers, r.Headers...)
+	}
+	for _, h := range headers {
+		if strings.EqualFold(h.Name, "Content-Type") {
+			return headers
+		}
+	}
+	if requestBody(e) != "" {
+		headers = append(headers, Header{Name: "Content-Type", Value: "application/json"})
+	}
+	return headers
+}
+
+// requestBody returns a request body for an Endpoint.  The body of the first
+// example request that has one is preferred, otherwise a JSON skeleton is
+// built from the documented body parameters.
+func requestBody(e *Endpoint) string {
+	for _, r := range e.Requests {
+		if r.Body != "" {
+			return r.Body
+		}
+	}
+
+	if len(e.DataParams) == 0 {
+		return ""
+	}
+	fields := make([]string, len(e.DataParams))
+	for i, p := range e.DataParams {
+		fields[i] = fmt.Sprintf("  %q: %s", p.Name, zeroValue(p.Type))
+	}
+	return "{\n" + strings.Join(fields, ",\n") + "\n}"
+}
+
+// zeroValue returns a JSON placeholder value for a documented parameter type.
+func zeroValue(typ string) string {
+	if t, ok := parseParamType(typ); ok {
+		return t.zeroValue()
+	}
+	return `""`
 }
@@ -0,0 +1,165 @@
+// The MIT License (MIT)
+//
+// Copyright (c) 2015 Dylan Carney
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package main
+
+const (
+
+	// the template for the Go client package generated by gen-client.  The
+	// output is run through gofmt, so the whitespace here doesn't need to be
+	// exact.
+	clientTemplate = `// Code generated by apidoc gen-client; DO NOT EDIT.
+
+package {{ .Package }}
+
+import (
+{{- if .HasBody }}
+	"bytes"
+	"encoding/json"
+{{- end }}
+	"context"
+{{- if .Errors }}
+	"errors"
+{{- end }}
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+)
+
+// Client calls the documented API endpoints.
+type Client struct {
+
+	// BaseURL is the scheme and host that the API is served from, e.g.
+	// "https://api.example.com"
+	BaseURL string
+
+	// HTTPClient sends the requests.  http.DefaultClient is used when nil.
+	HTTPClient *http.Client
+}
+
+// New returns a Client for the API served from baseURL.
+func New(baseURL string) *Client {
+	return &Client{BaseURL: strings.TrimSuffix(baseURL, "/")}
+}
+
+{{ if .Errors }}
+// Errors for the documented error responses.  An *Error unwraps to one of
+// these, so they can be checked with errors.Is.
+var (
+{{- range .Errors }}
+	{{ .Name }} = errors.New({{ printf "%q" .Text }})
+{{- end }}
+)
+{{ end }}
+// An Error is returned for any response with a status code of 400 or above.
+type Error struct {
+	StatusCode int
+	Body       []byte
+}
+
+func (e *Error) Error() string {
+	return fmt.Sprintf("%d %s: %s", e.StatusCode, http.StatusText(e.StatusCode), e.Body)
+}
+
+// Unwrap returns the error for the status code of e, if it is documented.
+func (e *Error) Unwrap() error {
+	switch e.StatusCode {
+{{- range .Errors }}
+	case {{ .Code }}:
+		return {{ .Name }}
+{{- end }}
+	}
+	return nil
+}
+
+func (c *Client) do(ctx context.Context, method, path string, query url.Values, body io.Reader) ([]byte, error) {
+	u := c.BaseURL + path
+	if len(query) > 0 {
+		u += "?" + query.Encode()
+	}
+	req, err := http.NewRequest(method, u, body)
+	if err != nil {
+		return nil, err
+	}
+	req = req.WithContext(ctx)
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+
+	hc := c.HTTPClient
+	if hc == nil {
+		hc = http.DefaultClient
+	}
+	resp, err := hc.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	b, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode >= 400 {
+		return nil, &Error{StatusCode: resp.StatusCode, Body: b}
+	}
+	return b, nil
+}
+{{ range $m := .Methods }}
+{{ if $m.Options }}
+// {{ $m.Name }}Options holds the optional and body parameters of {{ $m.Name }}.
+type {{ $m.Name }}Options struct {
+{{- range $m.Options }}
+{{ if .Doc }}	// {{ .Doc }}
+{{ end }}	{{ .Field }} {{ .Type }} ` + "`" + `json:"{{ if .Query }}-{{ else }}{{ .Param }}{{ if not .Required }},omitempty{{ end }}{{ end }}"` + "`" + `
+{{- end }}
+}
+{{ end }}
+{{ range $m.Doc }}// {{ . }}
+{{ end -}}
+func (c *Client) {{ $m.Name }}(ctx context.Context{{ range $m.PathArgs }}, {{ .Name }} {{ .Type }}{{ end }}{{ if $m.Options }}, opts *{{ $m.Name }}Options{{ end }}) ([]byte, error) {
+	path := fmt.Sprintf({{ printf "%q" $m.Path }}{{ range $m.PathArgs }}, {{ if .Wildcard }}(&url.URL{Path: fmt.Sprint({{ .Name }})}).EscapedPath(){{ else }}url.PathEscape(fmt.Sprint({{ .Name }})){{ end }}{{ end }})
+	query := url.Values{}
+	var body io.Reader
+{{- if $m.Options }}
+	if opts == nil {
+		opts = &{{ $m.Name }}Options{}
+	}
+{{- range $m.Options }}{{ if .Query }}
+	{{ if not .Required }}if {{ .NonZero }} {
+		{{ end }}query.Set({{ printf "%q" .Param }}, fmt.Sprint(opts.{{ .Field }})){{ if not .Required }}
+	}{{ end }}
+{{- end }}{{ end }}
+{{- if $m.HasBody }}
+	b, err := json.Marshal(opts)
+	if err != nil {
+		return nil, err
+	}
+	body = bytes.NewReader(b)
+{{- end }}
+{{- end }}
+	return c.do(ctx, {{ printf "%q" $m.Method }}, path, query, body)
+}
+{{ end }}`
+)
@@ -0,0 +1,180 @@
+// The MIT License (MIT)
+//
+// Copyright (c) 2015 Dylan Carney
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package main
+
+const (
+
+	// the stylesheet that is inlined into HTML output, so that it renders
+	// without any network access.  The colors are CSS variables, selected by
+	// the data-theme attribute on the <html> element.
+	stylesheet = `
+:root, [data-theme="light"] {
+  --bg: #fff;
+  --fg: #333;
+  --muted: #777;
+  --link: #337ab7;
+  --border: #ddd;
+  --pre-bg: #f5f5f5;
+  --pre-border: #ccc;
+  --code-fg: #c7254e;
+  --code-bg: #f9f2f4;
+  --error-fg: #a94442;
+  --error-bg: #f2dede;
+  --warning-fg: #8a6d3b;
+  --warning-bg: #fcf8e3;
+}
+[data-theme="dark"] {
+  --bg: #1e1f22;
+  --fg: #dcdcdc;
+  --muted: #9a9a9a;
+  --link: #6ab0f3;
+  --border: #3c3f44;
+  --pre-bg: #2b2d31;
+  --pre-border: #3c3f44;
+  --code-fg: #f38ba8;
+  --code-bg: #2b2d31;
+  --error-fg: #f2b8b5;
+  --error-bg: #601410;
+  --warning-fg: #f9e2af;
+  --warning-bg: #4a3b12;
+}
+@media (prefers-color-scheme: dark) {
+  [data-theme="auto"] {
+    --bg: #1e1f22;
+    --fg: #dcdcdc;
+    --muted: #9a9a9a;
+    --link: #6ab0f3;
+    --border: #3c3f44;
+    --pre-bg: #2b2d31;
+    --pre-border: #3c3f44;
+    --code-fg: #f38ba8;
+    --code-bg: #2b2d31;
+    --error-fg: #f2b8b5;
+    --error-bg: #601410;
+    --warning-fg: #f9e2af;
+    --warning-bg: #4a3b12;
+  }
+}
+* { box-sizing: border-box; }
+body {
+  margin: 0;
+  font-family: "Helvetica Neue", Helvetica, Arial, sans-serif;
+  font-size: 14px;
+  line-height: 1.42857143;
+  color: var(--fg);
+  background-color: var(--bg);
+}
+a { color: var(--link); text-decoration: none; }
+a:hover { text-decoration: underline; }
+.container { max-width: 1170px; margin: 0 auto; padding: 0 15px; }
+h2, h3, h4 { font-weight: 500; line-height: 1.1; margin: 20px 0 10px; }
+h3 { font-size: 24px; border-top: 1px solid var(--border); padding-top: 20px; }
+h1 { font-size: 36px; font-weight: 500; margin: 20px 0 10px; }
+h1 small { font-size: 65%; color: var(--muted); }
+h2 { font-size: 30px; margin-top: 40px; }
+dl { margin: 0 0 20px; }
+dt { font-weight: bold; margin-top: 5px; }
+dd { margin-left: 0; }
+h4 { font-size: 18px; }
+.tag {
+  display: inline-block;
+  margin-right: 5px;
+  padding: 1px 8px;
+  font-size: 12px;
+  color: var(--muted);
+  border: 1px solid var(--border);
+  border-radius: 10px;
+}
+.auth {
+  display: inline-block;
+  margin-right: 5px;
+  padding: 1px 8px;
+  font-size: 12px;
+  color: var(--bg);
+  background-color: var(--link);
+  border-radius: 10px;
+}
+.auth-none { background-color: var(--muted); }
+.error-model { margin-left: 5px; font-size: 12px; color: var(--muted); }
+.deprecated a, h3.deprecated { text-decoration: line-through; }
+.deprecation { margin: 0 0 10px; padding: 10px 15px; color: var(--warning-fg); background-color: var(--warning-bg); border-radius: 4px; }
+.deprecation p:last-child { margin-bottom: 0; }
+p, ul { margin: 0 0 10px; }
+pre, code { font-family: Menlo, Monaco, Consolas, "Courier New", monospace; }
+pre {
+  display: block;
+  margin: 0 0 10px;
+  padding: 9.5px;
+  font-size: 13px;
+  overflow: auto;
+  white-space: pre;
+  background-color: var(--pre-bg);
+  border: 1px solid var(--pre-border);
+  border-radius: 4px;
+}
+code {
+  padding: 2px 4px;
+  font-size: 90%;
+  color: var(--code-fg);
+  background-color: var(--code-bg);
+  border-radius: 4px;
+}
+pre code { padding: 0; font-size: inherit; color: inherit; background-color: transparent; }
+.errors { margin: 20px 0; padding: 10px 15px; color: var(--error-fg); background-color: var(--error-bg); border-radius: 4px; }
+.errors h4 { margin-top: 0; }
+.code-samples { display: flex; flex-wrap: wrap; }
+.code-samples input { display: none; }
+.code-samples label { padding: 4px 12px; cursor: pointer; border-bottom: 2px solid transparent; }
+.code-samples input:checked + label { border-bottom-color: var(--link); }
+.code-samples pre { display: none; order: 1; width: 100%; }
+.code-samples input:checked + label + pre { display: block; }
+.site { display: flex; align-items: flex-start; }
+.sidebar {
+  position: sticky;
+  top: 0;
+  flex: 0 0 280px;
+  height: 100vh;
+  overflow-y: auto;
+  padding: 15px;
+  border-right: 1px solid var(--border);
+}
+.sidebar .site-title { display: block; font-size: 18px; margin-bottom: 10px; color: var(--fg); }
+.sidebar input {
+  width: 100%;
+  padding: 6px 8px;
+  margin-bottom: 10px;
+  font: inherit;
+  color: var(--fg);
+  background-color: var(--bg);
+  border: 1px solid var(--border);
+  border-radius: 4px;
+}
+.sidebar h5 { margin: 15px 0 5px; font-size: 12px; text-transform: uppercase; color: var(--muted); }
+.sidebar ul { list-style: none; padding: 0; }
+.sidebar li { margin: 2px 0; overflow: hidden; text-overflow: ellipsis; white-space: nowrap; }
+.method { display: inline-block; min-width: 56px; font-size: 11px; font-weight: bold; color: var(--muted); }
+.content { flex: 1; min-width: 0; padding: 0 30px 30px; }
+table { width: 100%; border-collapse: collapse; margin-bottom: 20px; }
+th, td { text-align: left; padding: 6px 8px; border-bottom: 1px solid var(--border); vertical-align: top; }
+`
+)
@@ -23,19 +23,158 @@
 package main
 
 const (
+
+	// the template for an HTML page.  The stylesheet is inlined so that the
+	// page renders without any network access.
 	htmlTemplate = `
 <!DOCTYPE html>
-<html lang="en">
+<html lang="en" data-theme="{{ .Theme }}">
   <head>
     <meta charset="utf-8">
     <meta http-equiv="X-UA-Compatible" content="IE=edge">
     <meta name="viewport" content="width=device-width, initial-scale=1">
-    <title>apidoc</title>
-    <link rel="stylesheet" href="https://maxcdn.bootstrapcdn.com/bootstrap/3.3.4/css/bootstrap.min.css">
+    <title>{{ with .API }}{{ .Title }}{{ else }}apidoc{{ end }}</title>
+    <style>{{ .Stylesheet }}</style>
   </head>
   <body>
 		<div class="container">
-			<h3> {{ .Method }} [{{ .URLTemplate }}] </h3>
+			{{ if .Errors }}
+			<div class="errors">
+				<h4>Errors</h4>
+				<ul>
+					{{ range $err := .Errors }}
+					<li>{{ html $err }}</li>
+					{{ end }}
+				</ul>
+			</div>
+			{{ end }}
+
+			{{ with .API }}
+			{{ template "api" . }}
+			{{ end }}
+
+			{{ with .Matrix }}
+			{{ template "versions" . }}
+			{{ end }}
+
+			{{ if .Groups }}
+			{{ range $group := .Groups }}
+			<section data-version="{{ $group.Version }}">
+			<h2 id="tag-{{ anchor $group.Name }}{{ with $group.Version }}-{{ anchor . }}{{ end }}">{{ $group.Name }}{{ with $group.Version }} <small>{{ . }}</small>{{ end }}</h2>
+			{{ with $group.Description }}<p>{{ . }}</p>{{ end }}
+			{{ range $endpoint := $group.Endpoints }}
+			{{ template "endpoint" $endpoint }}
+			{{ end }}
+			</section>
+			{{ end }}
+			{{ else }}
+			{{ range $endpoint := .Endpoints }}
+			{{ template "endpoint" $endpoint }}
+			{{ end }}
+			{{ end }}
+		</div>
+		{{ if .LiveReload }}
+		<script>new EventSource("/_apidoc/events").onmessage = function () { location.reload(); };</script>
+		{{ end }}
+  </body>
+</html>
+`
+
+	// the header of an HTML page, describing the API as a whole
+	htmlAPITemplate = `
+			<header class="api">
+				<h1>{{ .Title }}{{ with .Version }} <small>{{ . }}</small>{{ end }}</h1>
+				{{ range $p := paragraphs .Description }}
+				<p>{{ $p }}</p>
+				{{ end }}
+				<dl>
+					{{ if .Servers }}
+					<dt>Servers</dt>
+					{{ range $server := .Servers }}
+					<dd>{{ with $server.Name }}{{ . }}: {{ end }}<code>{{ $server.URL }}</code></dd>
+					{{ end }}
+					{{ end }}
+					{{ if .Auth }}
+					<dt>Authentication</dt>
+					{{ range $scheme := .Auth }}
+					<dd><code>{{ $scheme.Name }}</code>{{ with $scheme.Description }} {{ . }}{{ end }}</dd>
+					{{ end }}
+					{{ end }}
+					{{ with .Contact }}<dt>Contact</dt><dd>{{ . }}</dd>{{ end }}
+					{{ with .License }}<dt>License</dt><dd>{{ . }}</dd>{{ end }}
+				</dl>
+			</header>
+`
+
+	// the version switcher and the matrix of which endpoints exist in which API
+	// version.  Switching versions hides the sections of the other versions.
+	htmlVersionsTemplate = `
+			<h2 id="versions">Versions</h2>
+			<p>
+				<label for="version">Show version</label>
+				<select id="version">
+					<option value="">all</option>
+					{{ range $v := .Versions }}<option>{{ $v }}</option>{{ end }}
+				</select>
+			</p>
+			<table class="versions">
+				<tr><th>Endpoint</th>{{ range $v := .Versions }}<th>{{ $v }}</th>{{ end }}</tr>
+				{{ range $row := .Rows }}
+				<tr>
+					<td><code>{{ $row.Route }}</code></td>
+					{{ range $e := $row.Endpoints }}<td>{{ if $e }}<a href="#{{ anchor $e.Name }}">{{ if $e.Deprecated }}deprecated{{ else }}✓{{ end }}</a>{{ end }}</td>{{ end }}
+				</tr>
+				{{ end }}
+			</table>
+			<script>
+				(function () {
+					var version = document.getElementById("version");
+					version.addEventListener("change", function () {
+						document.querySelectorAll("section[data-version]").forEach(function (section) {
+							var v = section.getAttribute("data-version");
+							section.style.display = !version.value || !v || v === version.value ? "" : "none";
+						});
+					});
+				})();
+			</script>
+`
+
+	// the table of the URL or body parameters, or the response fields, of an
+	// Endpoint
+	htmlParamsTemplate = `
+			<table class="params">
+				<tr><th>Name</th><th>Type</th><th>Required</th><th>Constraints</th><th>Description</th></tr>
+				{{ range $param := . }}
+				<tr>
+					<td><code>{{ $param.Name }}</code></td>
+					<td>{{ $param.Type }}</td>
+					<td>{{ if $param.Required }}yes{{ end }}</td>
+					<td>{{ range $c := $param.Constraints }}<div>{{ html $c }}</div>{{ end }}</td>
+					<td>{{ $param.Description }}</td>
+				</tr>
+				{{ end }}
+			</table>
+`
+
+	// the template for a single Endpoint in an HTML page
+	htmlEndpointTemplate = `
+			<h3 id="{{ anchor .Name }}"{{ if .Deprecated }} class="deprecated"{{ end }}> {{ .Method }} [{{ .URLTemplate }}] </h3>
+
+			{{ with .OtherRoutes }}
+			<p class="routes"><em>Also served at:</em>{{ range $route := . }} <code>{{ $route }}</code>{{ end }}</p>
+			{{ end }}
+
+			{{ if .Deprecated }}
+			<div class="deprecation">
+				<p><strong>Deprecated:</strong> this endpoint is deprecated{{ with .DeprecatedSince }} since {{ . }}{{ end }}{{ with .Sunset }} and may be removed after {{ . }}{{ end }}.{{ with .Replacement }} Use <code>{{ . }}</code> instead.{{ end }}</p>
+				{{ range $p := paragraphs .DeprecationNote }}<p>{{ $p }}</p>{{ end }}
+				{{ with .DeprecationHeaders }}<p>Its responses carry the headers:{{ range $header := . }} <code>{{ $header.Name }}: {{ $header.Value }}</code>{{ end }}</p>{{ end }}
+			</div>
+			{{ end }}
+
+			{{ if or .Tags .Auth }}
+			<p>{{ with .Auth }}<span class="auth{{ if not .Required }} auth-none{{ end }}">{{ if .Required }}{{ .Scheme }}{{ else }}no auth{{ end }}</span>{{ end }}{{ range $tag := .Tags }}<span class="tag">{{ $tag }}</span>{{ end }}</p>
+			{{ end }}
 
 			<p>{{ .Description }}</p>
 
@@ -43,33 +182,33 @@ const (
 			<p><em>**NOTE:**</em> {{ .Notes }}</p>
 			{{end}}
 
+			{{ with .Auth }}
+			<h4>Authentication</h4>
+			<p>{{ if .Required }}Requires <code>{{ .Scheme }}</code> credentials{{ with .Scopes }} with the scopes: {{ range $i, $scope := . }}{{ if $i }}, {{ end }}<code>{{ $scope }}</code>{{ end }}{{ end }}.{{ else }}No authentication is required.{{ end }}</p>
+			{{ end }}
+
 			{{ if .URLParams }}
 			<h4>Parameters</h4>
-			<ul>
-				{{ range $param := .URLParams }}
-				  <li>{{ $param.Name }} ({{ if $param.Required }}required {{ end }}{{ if $param.Type }}{{ $param.Type }}{{ end }}) : {{ $param.Description }}</li>
-				{{ end }}
-			</ul>
+			{{ template "params" .URLParams }}
 			{{ end }}
 
 			{{ if .DataParams }}
 			<h4>Request Body Parameters</h4>
-      <ul>
-				{{ range $param := .DataParams }}
-				  <li>{{ $param.Name }} ({{ if $param.Required }}required {{ end }}{{ if $param.Type }}{{ $param.Type }}{{ end }}) : {{ $param.Description }}</li>
-				{{ end }}
-      </ul>
+			{{ template "params" .DataParams }}
 			{{ end }}
 
 			<h4>Example success response</h4>
 			<code>{{ .SuccessResponse.Code }}</code>:<span>{{ statusText .SuccessResponse.Code }}</span>
 			<pre>{{ .SuccessResponse.Content }}</pre>
+			{{ with .SuccessResponse.Fields }}{{ template "params" . }}{{ end }}
 
 			{{ if .ErrorResponses }}
 			<h4>Example error responses</h4>
 				{{ range $resp := .ErrorResponses }}
-				<code>{{ $resp.Code }}</code>:<span>{{ statusText $resp.Code }}</span>
+				<code>{{ $resp.Code }}</code>:<span>{{ statusText $resp.Code }}</span>{{ if $resp.Model }} <span class="error-model">{{ $resp.Model }}{{ with $resp.ContentType }}, {{ . }}{{ end }}</span>{{ end }}
+				{{ range $p := paragraphs $resp.Description }}<p>{{ $p }}</p>{{ end }}
 				<pre>{{ $resp.Content }}</pre>
+				{{ with $resp.Fields }}{{ template "params" . }}{{ end }}
 				{{ end }}
 			{{ end }}
 
@@ -80,8 +219,22 @@ const (
 				{{ end }}
 			{{ end }}
 
-    </div>
-  </body>
-</html>
+			{{ if .Requests }}
+			<h4>Example requests</h4>
+				{{ range $req := .Requests }}
+					<pre>{{ $req }}</pre>
+				{{ end }}
+			{{ end }}
+
+			{{ with .CodeSamples }}
+			<h4>Code samples</h4>
+			<div class="code-samples">
+				{{ range $i, $sample := . }}
+				<input type="radio" name="samples-{{ anchor $.Name }}" id="samples-{{ anchor $.Name }}-{{ $i }}"{{ if eq $i 0 }} checked{{ end }}>
+				<label for="samples-{{ anchor $.Name }}-{{ $i }}">{{ $sample.Label }}</label>
+				<pre><code class="language-{{ $sample.Lang }}">{{ html $sample.Code }}</code></pre>
+				{{ end }}
+			</div>
+			{{ end }}
 `
 )
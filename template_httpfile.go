@@ -0,0 +1,42 @@
+// The MIT License (MIT)
+//
+// Copyright (c) 2015 Dylan Carney
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package main
+
+const (
+
+	// the template for .http request files, as understood by the VS Code REST
+	// Client and the JetBrains HTTP Client.  The {{host}} variable is left for
+	// the editor's environment to supply.
+	httpFileTemplate = `
+### {{ if .Name }}{{ .Name }}: {{ end }}{{ .Method }} {{ .URLTemplate }}
+{{ if .Deprecated }}# DEPRECATED{{ with .Sunset }}, may be removed after {{ . }}{{ end }}{{ with .Replacement }}, use {{ . }} instead{{ end }}
+{{ end }}{{ if .Description }}{{ comment .Description }}
+{{ end }}{{ if .Name }}# @name {{ .Name }}
+{{ end }}{{ range $param := .PathParams }}@{{ varName $ $param }} = {{ paramValue $ $param }}
+{{ end }}{{ range $param := .QueryParams }}@{{ varName $ $param }} = {{ paramValue $ $param }}
+{{ end }}{{ .Method }} {{ requestURL . }}
+{{ range $header := requestHeaders . }}{{ $header.Name }}: {{ $header.Value }}
+{{ end }}{{ with requestBody . }}
+{{ . }}
+{{ end }}`
+)
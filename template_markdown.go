@@ -30,37 +30,58 @@ const (
 	markdownTemplate = `
 ### {{ .Method }} [{{ .URLTemplate }}]
 
-{{ .Description }}
+{{ with .OtherRoutes }}*Also served at:*{{ range $i, $route := . }}{{ if $i }},{{ end }} ` + "`" + `{{ $route }}` + "`" + `{{ end }}
+
+{{ end }}{{ if .Deprecated }}> **Deprecated:** this endpoint is deprecated{{ with .DeprecatedSince }} since {{ . }}{{ end }}{{ with .Sunset }} and may be removed after {{ . }}{{ end }}.{{ with .Replacement }} Use ` + "`" + `{{ . }}` + "`" + ` instead.{{ end }}
+{{ with .DeprecationNote }}>
+{{ quote . }}
+{{ end }}{{ with .DeprecationHeaders }}>
+> Its responses carry the headers:{{ range $header := . }} ` + "`" + `{{ $header.Name }}: {{ $header.Value }}` + "`" + `{{ end }}
+{{ end }}
+{{ end }}{{ if .Tags }}*Tags: {{ join .Tags ", " }}*
+
+{{ end }}{{ with .Auth }}` + "`" + `{{ if .Required }}auth: {{ .Scheme }}{{ else }}no auth{{ end }}` + "`" + `
+
+{{ end }}{{ .Description }}
 
 {{ if .Notes }}
 **NOTE:** {{ .Notes }}
 {{end}}
 
+{{ with .Auth }}
+#### Authentication
+{{ if .Required }}Requires ` + "`" + `{{ .Scheme }}` + "`" + ` credentials{{ with .Scopes }} with the scopes: {{ join . ", " }}{{ end }}.{{ else }}No authentication is required.{{ end }}
+{{ end }}
+
 {{ if .URLParams }}
 #### Parameters
-  {{ range $param := .URLParams }}
-  * {{ $param.Name }} ({{ if $param.Required }}required {{ end }}{{ if $param.Type }}{{ $param.Type }}{{ end }}) : {{ $param.Description }}
-  {{ end }}
+
+{{ template "params" .URLParams }}
 {{ end }}
 
 {{ if .DataParams }}
 #### Request Body Parameters
-  {{ range $param := .DataParams }}
-  * {{ $param.Name }} ({{ if $param.Required }}required {{ end }}{{ if $param.Type }}{{ $param.Type }}{{ end }}) : {{ $param.Description }}
-  {{ end }}
+
+{{ template "params" .DataParams }}
 {{ end }}
 
 #### Example success response
 ` + "`" + `{{ .SuccessResponse.Code }}` + "`" + `: {{ statusText .SuccessResponse.Code }}
 
     {{ .SuccessResponse.Content }}
+{{ with .SuccessResponse.Fields }}
+{{ template "params" . }}{{ end }}
 
 {{ if .ErrorResponses }}
 #### Example error responses
   {{ range $resp := .ErrorResponses }}
-  ` + "`" + `{{ $resp.Code }}` + "`" + `: {{ statusText $resp.Code }}
-
-    {{ $resp.Content }}
+  ` + "`" + `{{ $resp.Code }}` + "`" + `: {{ statusText $resp.Code }}{{ if $resp.Model }} (` + "`" + `{{ $resp.Model }}` + "`" + `{{ with $resp.ContentType }}, {{ . }}{{ end }}){{ end }}
+{{ with $resp.Description }}
+  {{ indent "  " . }}
+{{ end }}
+    {{ if $resp.Model }}{{ indent "    " $resp.Content }}{{ else }}{{ $resp.Content }}{{ end }}
+{{ with $resp.Fields }}
+{{ template "params" . }}{{ end }}
   {{ end }}
 {{ end }}
 
@@ -70,5 +91,67 @@ const (
     {{ $call }}
   {{ end }}
 {{ end }}
+
+{{ if .Requests }}
+#### Example requests
+  {{ range $req := .Requests }}
+` + "```http" + `
+{{ $req }}
+` + "```" + `
+  {{ end }}
+{{ end }}
+
+{{ with .CodeSamples }}
+#### Code samples
+  {{ range $sample := . }}
+**{{ $sample.Label }}**
+
+` + "```" + `{{ $sample.Lang }}
+{{ $sample.Code }}
+` + "```" + `
+  {{ end }}
+{{ end }}
 `
+
+	// the table of the URL or body parameters, or the response fields, of an
+	// Endpoint
+	markdownParamsTemplate = `| Name | Type | Required | Constraints | Description |
+| --- | --- | --- | --- | --- |
+{{ range $param := . }}| {{ $param.Name }} | {{ cell $param.Type }} | {{ if $param.Required }}yes{{ end }} | {{ cell (join $param.Constraints ", ") }} | {{ cell $param.Description }} |
+{{ end }}`
+
+	// the header of combined output, describing the API as a whole
+	markdownAPITemplate = `# {{ .Title }}{{ with .Version }} ({{ . }}){{ end }}
+{{ with .Description }}
+{{ . }}
+{{ end }}{{ if .Servers }}
+**Servers:**
+{{ range $server := .Servers }}
+* {{ with $server.Name }}{{ . }}: {{ end }}` + "`" + `{{ $server.URL }}` + "`" + `{{ end }}
+{{ end }}{{ if .Auth }}
+**Authentication:**
+{{ range $scheme := .Auth }}
+* ` + "`" + `{{ $scheme.Name }}` + "`" + `{{ with $scheme.Description }}: {{ . }}{{ end }}{{ end }}
+{{ end }}{{ with .Contact }}
+**Contact:** {{ . }}
+{{ end }}{{ with .License }}
+**License:** {{ . }}
+{{ end }}`
+
+	// the matrix of which endpoints exist in which API version
+	markdownVersionsTemplate = `
+## Versions
+
+| Endpoint |{{ range $v := .Versions }} {{ $v }} |{{ end }}
+| --- |{{ range .Versions }} --- |{{ end }}
+{{ range $row := .Rows }}| ` + "`" + `{{ $row.Route }}` + "`" + ` |{{ range $e := $row.Endpoints }} {{ if $e }}{{ if $e.Deprecated }}deprecated{{ else }}✓{{ end }}{{ end }} |{{ end }}
+{{ end }}`
+
+	// the heading of a group of endpoints, with the API version when they are
+	// grouped by version
+	markdownTagTemplate = `
+## {{ .Name }}{{ with .Version }} ({{ . }}){{ end }}
+{{ with .Description }}
+{{ . }}
+{{ end }}`
 )
@@ -0,0 +1,107 @@
+// The MIT License (MIT)
+//
+// Copyright (c) 2015 Dylan Carney
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package main
+
+const (
+
+	// the template for every page of a static site.  Pages share the sidebar,
+	// and either show a single Endpoint or the index of all of them.
+	siteTemplate = `<!DOCTYPE html>
+<html lang="en" data-theme="{{ .Theme }}">
+  <head>
+    <meta charset="utf-8">
+    <meta http-equiv="X-UA-Compatible" content="IE=edge">
+    <meta name="viewport" content="width=device-width, initial-scale=1">
+    <title>{{ with .Endpoint }}{{ .Method }} {{ .URLTemplate }} - {{ end }}{{ with .API }}{{ .Title }}{{ else }}API reference{{ end }}</title>
+    <style>{{ .Stylesheet }}</style>
+  </head>
+  <body>
+    <div class="site">
+      <nav class="sidebar">
+        <a class="site-title" href="{{ .Root }}index.html">{{ with .API }}{{ .Title }}{{ else }}API reference{{ end }}</a>
+        <input type="search" id="search" placeholder="Search endpoints" autocomplete="off">
+        {{ range $group := .Groups }}
+        <div class="group">
+          <h5>{{ $group.Name }}{{ with $group.Version }} ({{ . }}){{ end }}</h5>
+          <ul>
+            {{ range $endpoint := $group.Endpoints }}
+            <li data-search="{{ html (searchText $endpoint) }}"{{ if $endpoint.Deprecated }} class="deprecated"{{ end }}><a href="{{ $.Root }}{{ permalink $endpoint }}"><span class="method">{{ $endpoint.Method }}</span>{{ $endpoint.URLTemplate }}</a></li>
+            {{ end }}
+          </ul>
+        </div>
+        {{ end }}
+      </nav>
+      <main class="content">
+        {{ if .Endpoint }}
+        {{ template "endpoint" .Endpoint }}
+        {{ else }}
+        {{ template "index" . }}
+        {{ end }}
+      </main>
+    </div>
+    <script>
+      (function () {
+        var search = document.getElementById("search");
+        search.addEventListener("input", function () {
+          var terms = search.value.toLowerCase().split(/\s+/);
+          document.querySelectorAll(".group").forEach(function (group) {
+            var visible = 0;
+            group.querySelectorAll("[data-search]").forEach(function (item) {
+              var text = item.getAttribute("data-search");
+              var match = terms.every(function (term) { return text.indexOf(term) >= 0; });
+              item.style.display = match ? "" : "none";
+              if (match) {
+                visible++;
+              }
+            });
+            group.style.display = visible ? "" : "none";
+          });
+        });
+      })();
+    </script>
+  </body>
+</html>
+`
+
+	// the template for the content of the index page of a static site
+	siteIndexTemplate = `
+{{ with .API }}
+{{ template "api" . }}
+{{ else }}
+<h1>API reference</h1>
+{{ end }}
+{{ range $group := .Groups }}
+<div class="group">
+  <h4>{{ $group.Name }}{{ with $group.Version }} <small>{{ . }}</small>{{ end }}</h4>
+  <table>
+    {{ range $endpoint := $group.Endpoints }}
+    <tr data-search="{{ html (searchText $endpoint) }}"{{ if $endpoint.Deprecated }} class="deprecated"{{ end }}>
+      <td><a href="{{ permalink $endpoint }}"><span class="method">{{ $endpoint.Method }}</span>{{ $endpoint.URLTemplate }}</a></td>
+      <td>{{ summary $endpoint }}</td>
+    </tr>
+    {{ end }}
+  </table>
+</div>
+{{ end }}
+`
+)
@@ -0,0 +1,59 @@
+// The MIT License (MIT)
+//
+// Copyright (c) 2015 Dylan Carney
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package main
+
+import "testing"
+
+func TestQuote(t *testing.T) {
+	tests := []struct {
+		text string
+		want string
+	}{
+		{"Use the v2 endpoint.", "> Use the v2 endpoint."},
+		{"Use the v2 endpoint,\nwhich pages.", "> Use the v2 endpoint,\n> which pages."},
+		{"First paragraph.\n\nSecond paragraph.", "> First paragraph.\n>\n> Second paragraph."},
+	}
+	for _, tt := range tests {
+		if got := quote(tt.text); got != tt.want {
+			t.Errorf("quote(%q) = %q, want %q", tt.text, got, tt.want)
+		}
+	}
+}
+
+func TestIndent(t *testing.T) {
+	tests := []struct {
+		prefix string
+		text   string
+		want   string
+	}{
+		{"  ", "The user was not found.", "The user was not found."},
+		{"  ", "The user was not found,\nor was deleted.", "The user was not found,\n  or was deleted."},
+		{"  ", "First paragraph.\n\nSecond paragraph.", "First paragraph.\n\n  Second paragraph."},
+		{"    ", "{\n  \"error\": \"string\"\n}", "{\n      \"error\": \"string\"\n    }"},
+	}
+	for _, tt := range tests {
+		if got := indent(tt.prefix, tt.text); got != tt.want {
+			t.Errorf("indent(%q, %q) = %q, want %q", tt.prefix, tt.text, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,105 @@
+// The MIT License (MIT)
+//
+// Copyright (c) 2015 Dylan Carney
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package main
+
+const (
+
+	// the template for the TypeScript module generated by gen-ts.
+	typescriptTemplate = `// Code generated by apidoc gen-ts; DO NOT EDIT.
+
+/** ApiError is thrown for any response with a status code of 400 or above. */
+export class ApiError extends Error {
+  constructor(public readonly status: number, public readonly body: string) {
+    super(status + ": " + body);
+  }
+}
+
+export interface ClientOptions {
+  /** baseURL is the scheme and host that the API is served from, e.g. "https://api.example.com" */
+  baseURL: string;
+  /** headers are sent with every request, e.g. for authentication */
+  headers?: Record<string, string>;
+  /** fetch sends the requests.  The global fetch is used when omitted. */
+  fetch?: typeof fetch;
+}
+{{ range $m := .Methods }}{{ if $m.Params }}
+/** {{ $m.Type }}Params holds the query and body parameters of {{ $m.Name }}. */
+export interface {{ $m.Type }}Params {
+{{- range $m.Params }}
+{{ if .Doc }}  /** {{ .Doc }} */
+{{ end }}  {{ .Key }}{{ if not .Required }}?{{ end }}: {{ .Type }};
+{{- end }}
+}
+{{ end }}
+/** {{ $m.Type }}Response is the body of a successful {{ $m.Name }} response. */
+export type {{ $m.Type }}Response = {{ $m.Response }};
+{{ end }}
+/** Client calls the documented API endpoints. */
+export class Client {
+  constructor(private readonly options: ClientOptions) {}
+
+  private async request<T>(method: string, path: string, query: Record<string, unknown>, body?: unknown): Promise<T> {
+    const url = new URL(this.options.baseURL.replace(/\/$/, "") + path);
+    for (const [key, value] of Object.entries(query)) {
+      if (value !== undefined) {
+        url.searchParams.set(key, String(value));
+      }
+    }
+
+    const headers: Record<string, string> = { ...this.options.headers };
+    if (body !== undefined) {
+      headers["Content-Type"] = "application/json";
+    }
+    const response = await (this.options.fetch ?? fetch)(url.toString(), {
+      method,
+      headers,
+      body: body === undefined ? undefined : JSON.stringify(body),
+    });
+
+    const text = await response.text();
+    if (!response.ok) {
+      throw new ApiError(response.status, text);
+    }
+    return (text ? JSON.parse(text) : undefined) as T;
+  }
+{{ range $m := .Methods }}
+  /**
+{{- range $m.Doc }}
+   *{{ if . }} {{ . }}{{ end }}
+{{- end }}
+   */
+  async {{ $m.Name }}({{ $m.Args }}): Promise<{{ $m.Type }}Response> {
+    const path = {{ $m.Path }};
+    return this.request<{{ $m.Type }}Response>({{ printf "%q" $m.Method }}, path, {{ if $m.HasQuery }}{
+{{- range $m.Params }}{{ if .Query }}
+      {{ printf "%q" .Param }}: {{ .Access }},
+{{- end }}{{ end }}
+    }{{ else }}{}{{ end }}{{ if $m.HasBody }}, {
+{{- range $m.Params }}{{ if not .Query }}
+      {{ printf "%q" .Param }}: {{ .Access }},
+{{- end }}{{ end }}
+    }{{ end }});
+  }
+{{ end }}}
+`
+)
@@ -23,9 +23,15 @@
 package main
 
 import (
+	"encoding/json"
 	"errors"
 	"fmt"
+	"net/http"
+	"net/url"
+	"regexp"
+	"strconv"
 	"strings"
+	"time"
 )
 
 type MissingURLParameterError string
@@ -34,25 +40,225 @@ func (e MissingURLParameterError) Error() string {
 	return fmt.Sprintf("apidoc: missing documentation for URL param: %s", string(e))
 }
 
+// An ExampleMismatchError reports an example request that doesn't match the
+// documented method or URL of its Endpoint.
+type ExampleMismatchError struct {
+
+	// Example is the method and URL used by the example request
+	Example string
+
+	// Documented is the method and URLTemplate of the Endpoint
+	Documented string
+}
+
+func (e ExampleMismatchError) Error() string {
+	return fmt.Sprintf("apidoc: example request %s doesn't match %s", e.Example, e.Documented)
+}
+
+// A ConstraintError reports a parameter constraint that is invalid, or a value
+// in an example request or default that doesn't satisfy it.
+type ConstraintError struct {
+	Param      string
+	Value      string // the value that doesn't satisfy the constraint, if any
+	Constraint string
+	Reason     string
+}
+
+func (e ConstraintError) Error() string {
+	switch {
+	case e.Value == "":
+		return fmt.Sprintf("apidoc: invalid constraint %s of param %s: %s", e.Constraint, e.Param, e.Reason)
+	case e.Reason != "":
+		return fmt.Sprintf("apidoc: value %q of param %s is %s", e.Value, e.Param, e.Reason)
+	}
+	return fmt.Sprintf("apidoc: value %q of param %s doesn't satisfy %s", e.Value, e.Param, e.Constraint)
+}
+
+// A RouteConflictError reports documented routes that differ from the routes
+// that the handler is registered for with a mux.
+type RouteConflictError struct {
+	Documented string
+	Registered string // empty when a documented route isn't registered
+}
+
+func (e RouteConflictError) Error() string {
+	if e.Registered == "" {
+		return fmt.Sprintf("apidoc: documented route %s isn't registered with a mux", e.Documented)
+	}
+	return fmt.Sprintf("apidoc: documented route %s doesn't match the registered route %s", e.Documented, e.Registered)
+}
+
+// A MissingResponseFieldError reports a documented response field that isn't
+// in the example content of the response.
+type MissingResponseFieldError struct {
+	Code  int
+	Field string
+}
+
+func (e MissingResponseFieldError) Error() string {
+	if e.Code == 0 {
+		return fmt.Sprintf("apidoc: response field %s isn't in the example", e.Field)
+	}
+	return fmt.Sprintf("apidoc: response field %s isn't in the example %d response", e.Field, e.Code)
+}
+
 var (
 	ErrMissingMethod = errors.New("apidoc: missing HTTP verb")
 	ErrMissingURL    = errors.New("apidoc: missing URL")
 )
 
+// An apiModel is the set of endpoints and tags documented in one or more Go
+// files.  It is what the renderers are given, and is written as-is by
+// -format=json, to be read back by the diff command.
+type apiModel struct {
+	API       *API
+	Tags      []Tag
+	Errors    []ErrorModel
+	Endpoints []*Endpoint
+}
+
+// An API describes the API as a whole, rather than any single Endpoint.  It
+// comes from a package-level apidoc-api(title) block, typically in doc.go.
+type API struct {
+
+	// Title is the name of the API, given in the apidoc-api(title) marker
+	Title string
+
+	// Version is the version of the API, e.g. "1.4.0"
+	Version string
+
+	// Servers are the base URLs that the API is served from
+	Servers []Server
+
+	// Contact is who to contact about the API, e.g. a team name and email
+	Contact string
+
+	// License is the license that the API is offered under
+	License string
+
+	// Auth are the named authentication schemes that endpoints can require
+	Auth []AuthScheme
+
+	// Description is an introduction to the API, in Markdown
+	Description string
+}
+
+// An AuthScheme is a named way of authenticating with the API, declared once
+// in the apidoc-api block, e.g. "Auth bearer OAuth2 access token".
+type AuthScheme struct {
+
+	// Name is what endpoints refer to the scheme by, e.g. "bearer"
+	Name string
+
+	// Description explains how to obtain and send the credentials
+	Description string
+}
+
+// AuthNone is the scheme of endpoints that don't require authentication.
+const AuthNone = "none"
+
+// Auth is the authentication that an Endpoint requires, given by its Auth
+// keyword, e.g. "Auth bearer scope:users.write" or "Auth none".
+type Auth struct {
+
+	// Scheme is the name of an AuthScheme, or AuthNone
+	Scheme string
+
+	// Scopes are the permissions that the credentials must grant
+	Scopes []string
+}
+
+// Required reports whether the Endpoint requires any authentication.
+func (a *Auth) Required() bool {
+	return a != nil && a.Scheme != AuthNone
+}
+
+func (a *Auth) String() string {
+	if a == nil {
+		return ""
+	}
+	s := a.Scheme
+	for _, scope := range a.Scopes {
+		s += " scope:" + scope
+	}
+	return s
+}
+
+// A Server is a base URL that the API is served from, for one environment.
+type Server struct {
+
+	// Name is the environment, e.g. "production" or "staging"
+	Name string
+
+	// URL is the base URL, e.g. "https://api.example.com"
+	URL string
+}
+
+// A Tag is a named group of endpoints.  Tags are described by a package-level
+// apidoc-tag(name) block, and endpoints are put in them with the Tags keyword.
+type Tag struct {
+
+	// Name is the identifier given in the apidoc-tag(name) marker
+	Name string
+
+	// Description is a human-readable description of the group of endpoints
+	Description string
+}
+
 // An Endpoint represents the pertinent documentatopn for a single HTTP API endpoint.
 type Endpoint struct {
 
+	// Name is the identifier given in the apidoc(name) marker
+	Name string
+
+	// Tags are the names of the groups that the endpoint belongs to.  The
+	// endpoint is listed under the first of them.
+	Tags []string
+
 	// Description is a human-readable description of the parameter and it's
 	// functionality
 	Description string
 
+	// Auth is the authentication that the endpoint requires, or nil if it
+	// isn't declared
+	Auth *Auth
+
+	// Deprecated marks an endpoint that clients should stop using
+	Deprecated bool
+
+	// DeprecatedSince is the date, as YYYY-MM-DD, from which an endpoint is
+	// deprecated, if it is known
+	DeprecatedSince string
+
+	// DeprecationNote is the text that follows the Deprecated line, e.g. to
+	// explain how to migrate away from the endpoint
+	DeprecationNote string
+
+	// Replacement is the apidoc(name) of the endpoint that replaces a
+	// deprecated one, if there is one
+	Replacement string
+
+	// Sunset is the date, as YYYY-MM-DD, from which a deprecated endpoint may
+	// be removed, if one is planned
+	Sunset string
+
+	// Version is the version of the API that the endpoint belongs to, given
+	// by the Version keyword or taken from a URL segment like "/v1/"
+	Version string
+
 	// Method is the HTTP request verb: e.g. GET, PUT, POST, DELETE
 	Method string
 
 	// URLTemplate is the URL structure of the endpoint, showing any URL params
-	// with colons, e.g. "/foobar/v1/hello/:firstName/:lastName"
+	// with colons, e.g. "/foobar/v1/hello/:firstName/:lastName", or in one of
+	// the other syntaxes described by pathSegment, e.g. "/hello/{firstName}"
 	URLTemplate string
 
+	// Routes are all of the methods and URLs that the endpoint is served at,
+	// e.g. both a legacy and a current path.  Method and URLTemplate are the
+	// first of them.
+	Routes []Route
+
 	// URLParams are the set of parameters that are specified in the URL of a
 	// request.
 	URLParams []Parameter
@@ -73,14 +279,26 @@ type Endpoint struct {
 	// to the given endpoint. A common use case is showing a curl command.
 	Examples []string
 
+	// Requests are the structured forms of the Examples that are curl
+	// commands
+	Requests []*Request
+
 	// Notes is a description of any important behaviors, side-effects, or other
 	// pertinent details of the endpoint
 	Notes string
+
+	// lastResponse is the response that Response Field sections add to, while
+	// the endpoint is being parsed
+	lastResponse *Response
 }
 
 // Validate ensure that all of the required fields are valid for an Endpoint.
-// Currently that simply means: the HTTP method and URL are specified, and
-// any params referenced in the URL have corresponding Parameter instances.
+// Currently that simply means: the HTTP method and URL are specified, any
+// params referenced in the URL of any of its routes have corresponding
+// Parameter instances, the types and constraints of the params are valid, the
+// documented response fields appear in the example responses, and any curl
+// examples use one of the documented routes, with param values that satisfy
+// the constraints.
 func (e Endpoint) Validate() error {
 	if e.Method == "" {
 		return ErrMissingMethod
@@ -90,14 +308,201 @@ func (e Endpoint) Validate() error {
 		return ErrMissingURL
 	}
 
-	for _, split := range strings.Split(e.URLTemplate, "/") {
-		if strings.HasPrefix(split, ":") && !contains(e.URLParams, split[1:]) {
-			return MissingURLParameterError(split[1:])
+	for _, route := range e.AllRoutes() {
+		for _, seg := range pathSegments(route.URLTemplate) {
+			if seg.Param != "" && !contains(e.URLParams, seg.Param) {
+				return MissingURLParameterError(seg.Param)
+			}
+			if _, err := seg.compile(); err != nil {
+				return fmt.Errorf("apidoc: invalid pattern of URL param %s: %s", seg.Param, err)
+			}
+		}
+	}
+
+	for _, p := range append(e.URLParams[:len(e.URLParams):len(e.URLParams)], e.DataParams...) {
+		if _, ok := parseParamType(p.Type); !ok {
+			return UnknownTypeError{Param: p.Name, Type: p.Type}
+		}
+		if err := p.checkConstraints(); err != nil {
+			return err
+		}
+	}
+
+	for _, r := range append([]Response{e.SuccessResponse}, e.ErrorResponses...) {
+		if err := r.checkFields(); err != nil {
+			return err
+		}
+	}
+
+	for _, example := range e.Examples {
+		r, err := parseCurl(example)
+		if err == ErrNotCurl {
+			continue
+		}
+		if err != nil {
+			return err
+		}
+		values, ok := e.matchRoute(r.Method, r.Path())
+		if !ok {
+			var documented []string
+			for _, route := range e.AllRoutes() {
+				documented = append(documented, route.String())
+			}
+			return ExampleMismatchError{
+				Example:    r.Method + " " + r.Path(),
+				Documented: strings.Join(documented, " or "),
+			}
+		}
+		if err := e.checkExample(r, values); err != nil {
+			return err
 		}
 	}
 	return nil
 }
 
+// checkExample checks the param values of an example request against the
+// constraints of the params.  values are the values of the path params.
+func (e Endpoint) checkExample(r *Request, values map[string]string) error {
+	query := r.Query()
+	for _, p := range e.URLParams {
+		v, ok := values[p.Name]
+		if !ok {
+			v, ok = query.Get(p.Name), query.Has(p.Name)
+		}
+		if ok {
+			if err := p.check(v); err != nil {
+				return err
+			}
+		}
+	}
+
+	var body map[string]interface{}
+	if len(e.DataParams) == 0 || json.Unmarshal([]byte(r.Body), &body) != nil {
+		return nil
+	}
+	for _, p := range e.DataParams {
+		switch v := body[p.Name].(type) {
+		case string, float64, bool:
+			if err := p.check(fmt.Sprint(v)); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// sunsetLayout is the time layout of the DeprecatedSince and Sunset dates of
+// an Endpoint.
+const sunsetLayout = "2006-01-02"
+
+// DeprecationHeaders returns the response headers that announce the
+// deprecation of an Endpoint, i.e. a Deprecation header, and a Sunset header
+// (RFC 8594) if a sunset date is planned.  Middleware can send these with
+// every response from a deprecated endpoint.  The Deprecation header gives
+// the date of the deprecation as RFC 9745 does, e.g. "@1767225600", when it
+// is known, and is "true" otherwise, as in the drafts that preceded it.
+func (e Endpoint) DeprecationHeaders() []Header {
+	if !e.Deprecated {
+		return nil
+	}
+	headers := []Header{{Name: "Deprecation", Value: "true"}}
+	if since, err := time.Parse(sunsetLayout, e.DeprecatedSince); err == nil {
+		headers[0].Value = fmt.Sprintf("@%d", since.Unix())
+	}
+	if sunset, err := time.Parse(sunsetLayout, e.Sunset); err == nil {
+		headers = append(headers, Header{Name: "Sunset", Value: sunset.Format(http.TimeFormat)})
+	}
+	return headers
+}
+
+// matchRoute reports whether a request method and path match any of the
+// routes of an Endpoint, and returns the values it gives to the URL params.
+func (e Endpoint) matchRoute(method, path string) (map[string]string, bool) {
+	for _, route := range e.AllRoutes() {
+		if values, ok := matchTemplate(route.URLTemplate, path); ok && route.Method == method {
+			return values, true
+		}
+	}
+	return nil, false
+}
+
+// matchPath reports whether a request path matches the URLTemplate, and
+// returns the values it gives to the URL params.
+func (e Endpoint) matchPath(path string) (map[string]string, bool) {
+	return matchTemplate(e.URLTemplate, path)
+}
+
+// matchTemplate reports whether a request path matches a URL template, and
+// returns the values it gives to the URL params.  A trailing "/" is ignored,
+// unless the template ends in {$}.
+func matchTemplate(urlTemplate, path string) (map[string]string, bool) {
+	want, got := urlTemplate, path
+	if !strings.HasSuffix(want, "/{$}") {
+		want, got = strings.TrimSuffix(want, "/"), strings.TrimSuffix(got, "/")
+	}
+	splits := strings.Split(got, "/")
+
+	values := map[string]string{}
+	for i, seg := range pathSegments(want) {
+		switch {
+		case seg.End:
+			return values, i == len(splits)-1 && splits[i] == ""
+		case seg.Wildcard:
+			if i >= len(splits) {
+				return nil, false
+			}
+			values[seg.Param] = strings.Join(splits[i:], "/")
+			return values, true
+		case i >= len(splits):
+			return nil, false
+		case seg.Param != "":
+			if splits[i] == "" {
+				return nil, false
+			}
+			if rx, err := seg.compile(); err == nil && rx != nil && !rx.MatchString(splits[i]) {
+				return nil, false
+			}
+			values[seg.Param] = splits[i]
+		case seg.Literal != splits[i]:
+			return nil, false
+		}
+	}
+	return values, len(splits) == len(pathSegments(want))
+}
+
+// PathParams returns the URLParams that appear as segments of the URLTemplate,
+// in the order they are documented.
+func (e Endpoint) PathParams() []Parameter {
+	var ps []Parameter
+	for _, p := range e.URLParams {
+		if e.hasPathParam(p.Name) {
+			ps = append(ps, p)
+		}
+	}
+	return ps
+}
+
+// QueryParams returns the URLParams that are not part of the URLTemplate, and
+// are therefore expected in the query string of a request.
+func (e Endpoint) QueryParams() []Parameter {
+	var ps []Parameter
+	for _, p := range e.URLParams {
+		if !e.hasPathParam(p.Name) {
+			ps = append(ps, p)
+		}
+	}
+	return ps
+}
+
+func (e Endpoint) hasPathParam(name string) bool {
+	for _, seg := range pathSegments(e.URLTemplate) {
+		if seg.Param == name {
+			return true
+		}
+	}
+	return false
+}
+
 func contains(ps []Parameter, name string) bool {
 	for _, p := range ps {
 		if p.Name == name {
@@ -107,6 +512,31 @@ func contains(ps []Parameter, name string) bool {
 	return false
 }
 
+// A Route is an HTTP method and URL template that an Endpoint is served at.
+type Route struct {
+	Method      string
+	URLTemplate string
+}
+
+func (r Route) String() string {
+	return r.Method + " " + r.URLTemplate
+}
+
+// AllRoutes returns the routes of an Endpoint, starting with its Method and
+// URLTemplate.  Endpoints from models without Routes have just the one.
+func (e Endpoint) AllRoutes() []Route {
+	if len(e.Routes) == 0 {
+		return []Route{{Method: e.Method, URLTemplate: e.URLTemplate}}
+	}
+	return e.Routes
+}
+
+// OtherRoutes returns the routes of an Endpoint besides its Method and
+// URLTemplate.
+func (e Endpoint) OtherRoutes() []Route {
+	return e.AllRoutes()[1:]
+}
+
 // A Response represents a type of HTTP response from an Endpoint.
 type Response struct {
 
@@ -115,6 +545,78 @@ type Response struct {
 
 	// ExampleContent shows a representative response body
 	Content string
+
+	// Fields describe the fields of the response body.  Their names are
+	// dotted paths, e.g. "items[].id"
+	Fields []Parameter
+
+	// Model is the name of the ErrorModel that describes the response body,
+	// if any.  The Content, Fields and ContentType of the model are used when
+	// the Response doesn't give its own.
+	Model string
+
+	// ContentType is the media type of the response body, when it comes
+	// from an ErrorModel
+	ContentType string
+
+	// Description describes the response body, when it comes from an
+	// ErrorModel
+	Description string
+}
+
+// checkFields reports whether the fields of a Response have valid types, and
+// appear in its example content, if that is JSON.
+func (r Response) checkFields() error {
+	var content interface{}
+	isJSON := json.Unmarshal([]byte(r.Content), &content) == nil
+	for _, f := range r.Fields {
+		if _, ok := parseParamType(f.Type); !ok {
+			return UnknownTypeError{Param: f.Name, Type: f.Type}
+		}
+		if isJSON && !hasJSONPath(content, strings.Split(f.Name, ".")) {
+			return MissingResponseFieldError{Code: r.Code, Field: f.Name}
+		}
+	}
+	return nil
+}
+
+// hasJSONPath reports whether a decoded JSON value has a field at the given
+// path, where a "[]" suffix steps into the elements of an array.  A field is
+// taken to be present in empty arrays, and in null values.
+func hasJSONPath(v interface{}, path []string) bool {
+	if len(path) == 0 || v == nil {
+		return true
+	}
+	obj, ok := v.(map[string]interface{})
+	if !ok {
+		return false
+	}
+	name := strings.TrimRight(path[0], "[]")
+	field, ok := obj[name]
+	if !ok {
+		return false
+	}
+	return hasElemPath(field, strings.Count(path[0][len(name):], "[]"), path[1:])
+}
+
+// hasElemPath steps depth levels into nested arrays before looking for path.
+func hasElemPath(v interface{}, depth int, path []string) bool {
+	if depth == 0 {
+		return hasJSONPath(v, path)
+	}
+	if v == nil {
+		return true
+	}
+	elems, ok := v.([]interface{})
+	if !ok {
+		return false
+	}
+	for _, elem := range elems {
+		if !hasElemPath(elem, depth-1, path) {
+			return false
+		}
+	}
+	return true
 }
 
 // A Parameter represents either a URL parameter or a request body parameter
@@ -130,10 +632,143 @@ type Parameter struct {
 	// specified
 	Required bool
 
-	// Type indicates the type of the parameter. e.g. string, numeric, etc.
+	// Type indicates the type of the parameter. e.g. string, integer, array
+	// of string, etc.  See ParamType for the type grammar.
 	Type string
 
 	// Description is a human-readable description of the parameter and it's
 	// functionality
 	Description string
+
+	// Default is the value that is used when the parameter isn't given
+	Default string
+
+	// Enum are the only values that the parameter may take, if set
+	Enum []string
+
+	// Min and Max are the inclusive bounds of a numeric parameter, if set
+	Min string
+	Max string
+
+	// Pattern is a regular expression that a string parameter must match
+	Pattern string
+
+	// Format is the format of a string parameter, e.g. "uuid" or "date-time"
+	Format string
+}
+
+// Constraints describes the constraints of a Parameter, one per item.
+func (p Parameter) Constraints() []string {
+	var cs []string
+	if p.Default != "" {
+		cs = append(cs, "default: "+p.Default)
+	}
+	if len(p.Enum) > 0 {
+		cs = append(cs, "one of: "+strings.Join(p.Enum, ", "))
+	}
+	if p.Min != "" {
+		cs = append(cs, "min: "+p.Min)
+	}
+	if p.Max != "" {
+		cs = append(cs, "max: "+p.Max)
+	}
+	if p.Pattern != "" {
+		cs = append(cs, "pattern: "+p.Pattern)
+	}
+	if p.Format != "" {
+		cs = append(cs, "format: "+p.Format)
+	}
+	return cs
+}
+
+// formatRx are the regular expressions for the values of the string formats
+// that are checked.
+var formatRx = map[string]*regexp.Regexp{
+	"uuid":  regexp.MustCompile(`^[0-9a-fA-F]{8}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{12}$`),
+	"email": regexp.MustCompile(`^[^@\s]+@[^@\s]+$`),
+}
+
+// checkConstraints reports whether the constraints of a Parameter are
+// themselves valid, e.g. that its pattern compiles and its default satisfies
+// the others.
+func (p Parameter) checkConstraints() error {
+	var min, max float64
+	var err error
+	if p.Min != "" {
+		if min, err = strconv.ParseFloat(p.Min, 64); err != nil {
+			return ConstraintError{Param: p.Name, Constraint: "min=" + p.Min, Reason: "not a number"}
+		}
+	}
+	if p.Max != "" {
+		if max, err = strconv.ParseFloat(p.Max, 64); err != nil {
+			return ConstraintError{Param: p.Name, Constraint: "max=" + p.Max, Reason: "not a number"}
+		}
+	}
+	if p.Min != "" && p.Max != "" && min > max {
+		return ConstraintError{Param: p.Name, Constraint: "min=" + p.Min, Reason: "greater than max=" + p.Max}
+	}
+	if p.Pattern != "" {
+		if _, err := regexp.Compile(p.Pattern); err != nil {
+			return ConstraintError{Param: p.Name, Constraint: "pattern=" + p.Pattern, Reason: err.Error()}
+		}
+	}
+	if p.Default != "" {
+		return p.check(p.Default)
+	}
+	return nil
+}
+
+// check reports whether a value satisfies the constraints of a Parameter.
+func (p Parameter) check(value string) error {
+	fail := func(constraint string) error {
+		return ConstraintError{Param: p.Name, Value: value, Constraint: constraint}
+	}
+
+	if len(p.Enum) > 0 {
+		found := false
+		for _, v := range p.Enum {
+			found = found || v == value
+		}
+		if !found {
+			return fail("enum=" + strings.Join(p.Enum, "|"))
+		}
+	}
+	if p.Min != "" || p.Max != "" {
+		n, err := strconv.ParseFloat(value, 64)
+		if err != nil {
+			return ConstraintError{Param: p.Name, Value: value, Reason: "not a number"}
+		}
+		if min, err := strconv.ParseFloat(p.Min, 64); err == nil && n < min {
+			return fail("min=" + p.Min)
+		}
+		if max, err := strconv.ParseFloat(p.Max, 64); err == nil && n > max {
+			return fail("max=" + p.Max)
+		}
+	}
+	if p.Pattern != "" {
+		if rx, err := regexp.Compile(p.Pattern); err == nil && !rx.MatchString(value) {
+			return fail("pattern=" + p.Pattern)
+		}
+	}
+
+	ok := true
+	switch p.Format {
+	case "date":
+		_, err := time.Parse("2006-01-02", value)
+		ok = err == nil
+	case "date-time":
+		_, err := time.Parse(time.RFC3339, value)
+		ok = err == nil
+	case "uri":
+		u, err := url.Parse(value)
+		ok = err == nil && u.Scheme != ""
+	default:
+		if rx := formatRx[p.Format]; rx != nil {
+			ok = rx.MatchString(value)
+		}
+	}
+	if !ok {
+		return fail("format=" + p.Format)
+	}
+	return nil
 }
@@ -0,0 +1,334 @@
+// The MIT License (MIT)
+//
+// Copyright (c) 2015 Dylan Carney
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"log"
+	"regexp"
+	"strings"
+	"text/template"
+)
+
+// tsFile is the data that typescriptTemplate is rendered with.
+type tsFile struct {
+	Methods []tsMethod
+}
+
+// tsMethod is a Client method that calls a single Endpoint.
+type tsMethod struct {
+	Name           string
+	Type           string // the prefix of the method's type names
+	Doc            []string
+	Method         string
+	Path           string // an expression that evaluates to the request path
+	PathArgs       []tsField
+	Args           string // the argument list of the method
+	Params         []tsField
+	ParamsRequired bool
+	HasQuery       bool
+	HasBody        bool
+	Response       string
+}
+
+// tsField is a parameter of a Client method, either as a function argument or
+// as a property of the method's params interface.
+type tsField struct {
+	Name     string // the argument name
+	Key      string // the property name, quoted if necessary
+	Access   string // an expression that reads the property from params
+	Param    string // the documented parameter name
+	Type     string
+	Doc      string
+	Required bool
+	Query    bool
+}
+
+// genTypeScript implements the gen-ts command, which writes a TypeScript
+// module for the endpoints documented in the given files.
+func genTypeScript(args []string) {
+	fs := flag.NewFlagSet("gen-ts", flag.ExitOnError)
+	out := fs.String("out", "client.ts", "Name of the output file to use.")
+	fs.BoolVar(&opts.check, "check", false, "Generates the output without writing it, printing a diff and exiting non-zero if the existing output file is out of date.")
+	fs.StringVar(&opts.rev, "rev", "", "Reads the input files as of a git revision from the repository's object store, instead of from the working tree.")
+	fs.BoolVar(&opts.strict, "strict", false, "Enables validation checks on each apidoc comment block. When strict is true, any validation error causes the process to exit.")
+	fs.Var(&opts.verbs, "verbs", "Comma-separated list of HTTP methods to recognize besides the standard ones, e.g. PROPFIND,REPORT,QUERY.")
+	fs.Parse(args)
+
+	endpoints := loadFiles(fs.Args()).Endpoints
+	src, err := generateTypeScript(endpoints)
+	if err != nil {
+		log.Fatalf("could not generate TypeScript: %s", err)
+	}
+	if err := emit(*out, src); err != nil {
+		log.Fatalf("could not write output file: %s", err)
+	}
+	exitIfStale()
+}
+
+// generateTypeScript renders a TypeScript module with a typed fetch wrapper
+// for each of the endpoints.
+func generateTypeScript(endpoints []*Endpoint) ([]byte, error) {
+	var f tsFile
+	names := map[string]bool{}
+	for _, e := range endpoints {
+		if e.Method == "" || e.URLTemplate == "" {
+			log.Printf("skipping apidoc(%s): missing method or URL\n", e.Name)
+			continue
+		}
+
+		m := newTSMethod(e)
+		if names[m.Name] {
+			return nil, fmt.Errorf("duplicate method name %s from apidoc(%s)", m.Name, e.Name)
+		}
+		names[m.Name] = true
+		f.Methods = append(f.Methods, m)
+	}
+
+	var b bytes.Buffer
+	t := template.Must(template.New("typescript").Parse(typescriptTemplate))
+	if err := t.Execute(&b, f); err != nil {
+		return nil, err
+	}
+	return b.Bytes(), nil
+}
+
+func newTSMethod(e *Endpoint) tsMethod {
+	m := tsMethod{
+		Name:     tsIdent(e.Name),
+		Type:     exportedName(e.Name),
+		Method:   e.Method,
+		HasBody:  len(e.DataParams) > 0,
+		Response: "unknown",
+	}
+
+	m.Doc = append(m.Doc, fmt.Sprintf("%s calls %s %s.", m.Name, e.Method, e.URLTemplate))
+	if e.Description != "" {
+		m.Doc = append(m.Doc, "")
+		m.Doc = append(m.Doc, strings.Split(strings.TrimSpace(e.Description), "\n")...)
+	}
+	if e.Deprecated {
+		m.Doc = append(m.Doc, "", "@deprecated "+m.Name+" is deprecated"+clientDeprecationNote(e, tsIdent)+".")
+	}
+	for i, line := range m.Doc {
+		m.Doc[i] = strings.Replace(line, "*/", `*\/`, -1)
+	}
+
+	var parts []string
+	literal := ""
+	for i, seg := range pathSegments(e.URLTemplate) {
+		if i > 0 {
+			literal += "/"
+		}
+		if seg.Param == "" {
+			literal += seg.Literal
+			continue
+		}
+		p := Parameter{Name: seg.Param}
+		for _, param := range e.URLParams {
+			if param.Name == p.Name {
+				p = param
+			}
+		}
+		f := newTSField(p)
+		m.PathArgs = append(m.PathArgs, f)
+		encode := "encodeURIComponent"
+		if seg.Wildcard {
+			// the rest of the path keeps its "/" separators
+			encode = "encodeURI"
+		}
+		parts = append(parts, fmt.Sprintf("%q", literal), fmt.Sprintf("%s(String(%s))", encode, f.Name))
+		literal = ""
+	}
+	if literal != "" || len(parts) == 0 {
+		parts = append(parts, fmt.Sprintf("%q", literal))
+	}
+	m.Path = strings.Join(parts, " + ")
+
+	for _, p := range e.QueryParams() {
+		f := newTSField(p)
+		f.Query = true
+		m.Params = append(m.Params, f)
+	}
+	for _, p := range e.DataParams {
+		m.Params = append(m.Params, newTSField(p))
+	}
+	var args []string
+	for _, f := range m.PathArgs {
+		args = append(args, f.Name+": "+f.Type)
+	}
+	for _, f := range m.Params {
+		m.ParamsRequired = m.ParamsRequired || f.Required
+		m.HasQuery = m.HasQuery || f.Query
+	}
+	switch {
+	case m.ParamsRequired:
+		args = append(args, "params: "+m.Type+"Params")
+	case len(m.Params) > 0:
+		args = append(args, "params?: "+m.Type+"Params")
+	}
+	m.Args = strings.Join(args, ", ")
+
+	if typ, ok := tsTypeOfJSON(e.SuccessResponse.Content); ok {
+		m.Response = typ
+	}
+	return m
+}
+
+func newTSField(p Parameter) tsField {
+	f := tsField{
+		Name:     tsIdent(p.Name),
+		Key:      tsKey(p.Name),
+		Param:    p.Name,
+		Type:     tsType(p.Type),
+		Doc:      strings.Replace(strings.TrimSpace(p.Description), "*/", `*\/`, -1),
+		Required: p.Required,
+	}
+	f.Access = "params?." + p.Name
+	if f.Key != p.Name {
+		f.Access = "params?.[" + f.Key + "]"
+	}
+	return f
+}
+
+// tsType maps a documented parameter type onto a TypeScript type.
+func tsType(typ string) string {
+	if t, ok := parseParamType(typ); ok {
+		return t.tsType()
+	}
+	return "unknown"
+}
+
+// tsTypeOfJSON infers a TypeScript type from an example JSON document.  The
+// order of object properties is kept, so the type reads like the example.
+func tsTypeOfJSON(content string) (string, bool) {
+	content = strings.TrimSpace(content)
+	if content == "" {
+		return "", false
+	}
+	d := json.NewDecoder(strings.NewReader(content))
+	d.UseNumber()
+	typ, err := tsTypeOfValue(d, "")
+	if err != nil {
+		return "", false
+	}
+	if _, err := d.Token(); err != io.EOF {
+		return "", false
+	}
+	return typ, true
+}
+
+func tsTypeOfValue(d *json.Decoder, indent string) (string, error) {
+	tok, err := d.Token()
+	if err != nil {
+		return "", err
+	}
+
+	switch tok := tok.(type) {
+	case json.Delim:
+		if tok == '[' {
+			elem := "unknown"
+			for i := 0; d.More(); i++ {
+				typ, err := tsTypeOfValue(d, indent)
+				if err != nil {
+					return "", err
+				}
+				if i == 0 {
+					elem = typ
+				}
+			}
+			if _, err := d.Token(); err != nil {
+				return "", err
+			}
+			if strings.ContainsAny(elem, " |") && !strings.HasPrefix(elem, "{") {
+				elem = "(" + elem + ")"
+			}
+			return elem + "[]", nil
+		}
+
+		var b strings.Builder
+		b.WriteString("{\n")
+		for d.More() {
+			key, err := d.Token()
+			if err != nil {
+				return "", err
+			}
+			typ, err := tsTypeOfValue(d, indent+"  ")
+			if err != nil {
+				return "", err
+			}
+			fmt.Fprintf(&b, "%s  %s: %s;\n", indent, tsKey(key.(string)), typ)
+		}
+		if _, err := d.Token(); err != nil {
+			return "", err
+		}
+		b.WriteString(indent + "}")
+		return b.String(), nil
+	case string:
+		return "string", nil
+	case json.Number:
+		return "number", nil
+	case bool:
+		return "boolean", nil
+	}
+	return "null", nil
+}
+
+var tsIdentRx = regexp.MustCompile(`^[A-Za-z_$][A-Za-z0-9_$]*$`)
+
+// tsKey returns name as a TypeScript property name, quoting it if necessary.
+func tsKey(name string) string {
+	if tsIdentRx.MatchString(name) {
+		return name
+	}
+	return fmt.Sprintf("%q", name)
+}
+
+// tsReserved are the JavaScript reserved words that can't be used as argument
+// names.
+var tsReserved = map[string]bool{
+	"break": true, "case": true, "catch": true, "class": true, "const": true,
+	"continue": true, "debugger": true, "default": true, "delete": true,
+	"do": true, "else": true, "enum": true, "export": true, "extends": true,
+	"false": true, "finally": true, "for": true, "function": true, "if": true,
+	"import": true, "in": true, "instanceof": true, "new": true, "null": true,
+	"return": true, "super": true, "switch": true, "this": true, "throw": true,
+	"true": true, "try": true, "typeof": true, "var": true, "void": true,
+	"while": true, "with": true, "params": true, "path": true,
+}
+
+// tsIdent turns a name such as "user-id" into a TypeScript identifier, e.g.
+// "userId".
+func tsIdent(name string) string {
+	r := []rune(exportedName(name))
+	r[0] = []rune(strings.ToLower(string(r[0])))[0]
+	s := string(r)
+	if tsReserved[s] {
+		return s + "Param"
+	}
+	return s
+}
@@ -0,0 +1,236 @@
+// The MIT License (MIT)
+//
+// Copyright (c) 2015 Dylan Carney
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package main
+
+import (
+	"fmt"
+	"strings"
+)
+
+// diffContext is the number of unchanged lines shown around each change in a
+// unified diff.
+const diffContext = 3
+
+// A diffOp is a single line of an edit script: ' ' for a line common to both
+// sides, '-' for a line only in the old text, '+' for a line only in the new.
+type diffOp struct {
+	kind byte
+	line string
+}
+
+// unifiedDiff returns a unified diff that turns oldText into newText, or ""
+// if they are equal.
+func unifiedDiff(oldName, newName, oldText, newText string) string {
+	if oldText == newText {
+		return ""
+	}
+	ops := diffLines(splitLines(oldText), splitLines(newText))
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "--- %s\n+++ %s\n", oldName, newName)
+
+	// walk the ops, emitting a hunk for each run of changes (and the changes
+	// within 2*diffContext lines of it)
+	oldLine, newLine := 1, 1
+	for i := 0; i < len(ops); {
+		if ops[i].kind == ' ' {
+			oldLine++
+			newLine++
+			i++
+			continue
+		}
+
+		start := i - diffContext
+		if start < 0 {
+			start = 0
+		}
+		end := i
+		for j := i; j < len(ops); j++ {
+			if ops[j].kind != ' ' {
+				end = j + 1
+			} else if j-end >= 2*diffContext {
+				break
+			}
+		}
+		end += diffContext
+		if end > len(ops) {
+			end = len(ops)
+		}
+
+		oldStart, newStart := oldLine-(i-start), newLine-(i-start)
+		var oldCount, newCount int
+		var hunk strings.Builder
+		for _, op := range ops[start:end] {
+			hunk.WriteByte(op.kind)
+			hunk.WriteString(op.line)
+			hunk.WriteByte('\n')
+			if op.kind != '+' {
+				oldCount++
+			}
+			if op.kind != '-' {
+				newCount++
+			}
+		}
+		fmt.Fprintf(&b, "@@ -%s +%s @@\n%s", hunkRange(oldStart, oldCount), hunkRange(newStart, newCount), hunk.String())
+
+		for _, op := range ops[i:end] {
+			if op.kind != '+' {
+				oldLine++
+			}
+			if op.kind != '-' {
+				newLine++
+			}
+		}
+		i = end
+	}
+	return b.String()
+}
+
+// hunkRange formats the line range of one side of a hunk.  An empty range is
+// given as the line before it, as diff(1) does.
+func hunkRange(start, count int) string {
+	if count == 0 {
+		start--
+	}
+	if count == 1 {
+		return fmt.Sprint(start)
+	}
+	return fmt.Sprintf("%d,%d", start, count)
+}
+
+func splitLines(text string) []string {
+	if text == "" {
+		return nil
+	}
+	return strings.Split(strings.TrimSuffix(text, "\n"), "\n")
+}
+
+// diffLines returns a shortest edit script from a to b, using the linear
+// space variant of Myers' O(ND) algorithm: the middle snake of a shortest
+// path is found, and the parts before and after it are diffed recursively.
+// Only the furthest reaching x of each diagonal is kept, so memory is linear
+// in the size of the input, however different the two sides are.
+func diffLines(a, b []string) []diffOp {
+	ops := make([]diffOp, 0, len(a)+len(b))
+	return diffRange(ops, a, b)
+}
+
+// diffRange appends a shortest edit script from a to b to ops.
+func diffRange(ops []diffOp, a, b []string) []diffOp {
+	// lines common to the start or end of both sides are kept as they are
+	prefix := 0
+	for prefix < len(a) && prefix < len(b) && a[prefix] == b[prefix] {
+		prefix++
+	}
+	for _, line := range a[:prefix] {
+		ops = append(ops, diffOp{' ', line})
+	}
+	a, b = a[prefix:], b[prefix:]
+
+	suffix := 0
+	for suffix < len(a) && suffix < len(b) && a[len(a)-1-suffix] == b[len(b)-1-suffix] {
+		suffix++
+	}
+	common := a[len(a)-suffix:]
+	a, b = a[:len(a)-suffix], b[:len(b)-suffix]
+
+	switch {
+	case len(a) == 0:
+		for _, line := range b {
+			ops = append(ops, diffOp{'+', line})
+		}
+	case len(b) == 0:
+		for _, line := range a {
+			ops = append(ops, diffOp{'-', line})
+		}
+	default:
+		x, y, u, v := middleSnake(a, b)
+		ops = diffRange(ops, a[:x], b[:y])
+		for _, line := range a[x:u] {
+			ops = append(ops, diffOp{' ', line})
+		}
+		ops = diffRange(ops, a[u:], b[v:])
+	}
+
+	for _, line := range common {
+		ops = append(ops, diffOp{' ', line})
+	}
+	return ops
+}
+
+// middleSnake returns the middle snake of a shortest edit script from a to
+// b, as the points (x, y) and (u, v) where it starts and ends.  Paths are
+// searched for from both ends at once, until they overlap.  Neither a nor b
+// may be empty, and they must differ in their first and last lines, so that
+// the snake splits the script into two shorter ones.
+func middleSnake(a, b []string) (x, y, u, v int) {
+	n, m := len(a), len(b)
+	max := (n + m + 1) / 2
+	off := max + 1
+	delta := n - m
+	odd := delta%2 != 0
+
+	// fwd holds the furthest reaching x of each diagonal k = x - y from the
+	// start, and bwd the furthest reaching distance from the end of each
+	// diagonal c = (n - x) - (m - y), i.e. k = delta - c
+	fwd := make([]int, 2*max+3)
+	bwd := make([]int, 2*max+3)
+	for d := 0; d <= max; d++ {
+		for k := -d; k <= d; k += 2 {
+			if k == -d || (k != d && fwd[off+k-1] < fwd[off+k+1]) {
+				x = fwd[off+k+1]
+			} else {
+				x = fwd[off+k-1] + 1
+			}
+			y = x - k
+			u, v = x, y
+			for u < n && v < m && a[u] == b[v] {
+				u++
+				v++
+			}
+			fwd[off+k] = u
+			if c := delta - k; odd && c >= -(d-1) && c <= d-1 && u+bwd[off+c] >= n {
+				return x, y, u, v
+			}
+		}
+		for c := -d; c <= d; c += 2 {
+			var rx int
+			if c == -d || (c != d && bwd[off+c-1] < bwd[off+c+1]) {
+				rx = bwd[off+c+1]
+			} else {
+				rx = bwd[off+c-1] + 1
+			}
+			ry := rx - c
+			ru, rv := rx, ry
+			for ru < n && rv < m && a[n-1-ru] == b[m-1-rv] {
+				ru++
+				rv++
+			}
+			bwd[off+c] = ru
+			if k := delta - c; !odd && k >= -d && k <= d && fwd[off+k]+ru >= n {
+				return n - ru, m - rv, n - rx, m - ry
+			}
+		}
+	}
+	panic("apidoc: no middle snake found")
+}
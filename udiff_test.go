@@ -0,0 +1,172 @@
+// The MIT License (MIT)
+//
+// Copyright (c) 2015 Dylan Carney
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package main
+
+import (
+	"fmt"
+	"math/rand"
+	"strings"
+	"testing"
+)
+
+// numbered returns the lines "1" to "n", each followed by a newline.
+func numbered(n int) string {
+	var b strings.Builder
+	for i := 1; i <= n; i++ {
+		fmt.Fprintf(&b, "%d\n", i)
+	}
+	return b.String()
+}
+
+func TestUnifiedDiff(t *testing.T) {
+	tests := []struct {
+		name     string
+		old, new string
+		want     string
+	}{
+		{
+			name: "equal",
+			old:  "a\nb\n",
+			new:  "a\nb\n",
+			want: "",
+		},
+		{
+			name: "empty old",
+			old:  "",
+			new:  "a\nb\nc\n",
+			want: "--- old\n+++ new\n@@ -0,0 +1,3 @@\n+a\n+b\n+c\n",
+		},
+		{
+			name: "empty new",
+			old:  "a\nb\nc\n",
+			new:  "",
+			want: "--- old\n+++ new\n@@ -1,3 +0,0 @@\n-a\n-b\n-c\n",
+		},
+		{
+			name: "single line",
+			old:  "a\n",
+			new:  "b\n",
+			want: "--- old\n+++ new\n@@ -1 +1 @@\n-a\n+b\n",
+		},
+		{
+			name: "one line changed",
+			old:  numbered(10),
+			new:  strings.Replace(numbered(10), "5\n", "five\n", 1),
+			want: "--- old\n+++ new\n@@ -2,7 +2,7 @@\n 2\n 3\n 4\n-5\n+five\n 6\n 7\n 8\n",
+		},
+		{
+			name: "line added at end",
+			old:  numbered(5),
+			new:  numbered(6),
+			want: "--- old\n+++ new\n@@ -3,3 +3,4 @@\n 3\n 4\n 5\n+6\n",
+		},
+		{
+			name: "nearby changes share a hunk",
+			old:  numbered(20),
+			new:  strings.Replace(strings.Replace(numbered(20), "5\n", "five\n", 1), "11\n", "eleven\n", 1),
+			want: "--- old\n+++ new\n@@ -2,13 +2,13 @@\n 2\n 3\n 4\n-5\n+five\n 6\n 7\n 8\n 9\n 10\n-11\n+eleven\n 12\n 13\n 14\n",
+		},
+		{
+			name: "distant changes get their own hunks",
+			old:  numbered(20),
+			new:  strings.Replace(strings.Replace(numbered(20), "\n2\n", "\ntwo\n", 1), "19\n", "nineteen\n", 1),
+			want: "--- old\n+++ new\n@@ -1,5 +1,5 @@\n 1\n-2\n+two\n 3\n 4\n 5\n@@ -16,5 +16,5 @@\n 16\n 17\n 18\n-19\n+nineteen\n 20\n",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := unifiedDiff("old", "new", tt.old, tt.new); got != tt.want {
+				t.Errorf("unifiedDiff() =\n%s\nwant:\n%s", got, tt.want)
+			}
+		})
+	}
+}
+
+// lcsLength returns the length of the longest common subsequence of a and b.
+func lcsLength(a, b []string) int {
+	prev := make([]int, len(b)+1)
+	for i := range a {
+		cur := make([]int, len(b)+1)
+		for j := range b {
+			switch {
+			case a[i] == b[j]:
+				cur[j+1] = prev[j] + 1
+			case prev[j+1] > cur[j]:
+				cur[j+1] = prev[j+1]
+			default:
+				cur[j+1] = cur[j]
+			}
+		}
+		prev = cur
+	}
+	return prev[len(b)]
+}
+
+func TestDiffLinesIsShortest(t *testing.T) {
+	rnd := rand.New(rand.NewSource(1))
+	lines := func() []string {
+		s := make([]string, rnd.Intn(30))
+		for i := range s {
+			s[i] = string(rune('a' + rnd.Intn(4)))
+		}
+		return s
+	}
+	for i := 0; i < 2000; i++ {
+		a, b := lines(), lines()
+		ops := diffLines(a, b)
+
+		var gotA, gotB []string
+		edits := 0
+		for _, op := range ops {
+			if op.kind != '+' {
+				gotA = append(gotA, op.line)
+			}
+			if op.kind != '-' {
+				gotB = append(gotB, op.line)
+			}
+			if op.kind != ' ' {
+				edits++
+			}
+		}
+		if strings.Join(gotA, ",") != strings.Join(a, ",") || strings.Join(gotB, ",") != strings.Join(b, ",") {
+			t.Fatalf("diffLines(%q, %q) = %v, which doesn't turn one into the other", a, b, ops)
+		}
+		if want := len(a) + len(b) - 2*lcsLength(a, b); edits != want {
+			t.Fatalf("diffLines(%q, %q) has %d edits, want %d", a, b, edits, want)
+		}
+	}
+}
+
+func TestUnifiedDiffLarge(t *testing.T) {
+	text := numbered(16000)
+	got := unifiedDiff("old", "new", "", text)
+	if !strings.HasPrefix(got, "--- old\n+++ new\n@@ -0,0 +1,16000 @@\n+1\n") {
+		t.Errorf("unifiedDiff() of a new file starts with %q", got[:40])
+	}
+
+	changed := strings.Replace(text, "\n8000\n", "\neight thousand\n", 1)
+	want := "--- old\n+++ new\n@@ -7997,7 +7997,7 @@\n 7997\n 7998\n 7999\n-8000\n+eight thousand\n 8001\n 8002\n 8003\n"
+	if got := unifiedDiff("old", "new", text, changed); got != want {
+		t.Errorf("unifiedDiff() =\n%s\nwant:\n%s", got, want)
+	}
+}